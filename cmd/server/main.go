@@ -2,47 +2,298 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"log"
+	"log/slog"
 	"net/http"
+	"net/http/pprof"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/clock"
 	"github.com/Amir-Golmoradi/Customer-Management-System/internal/config"
 	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
 	"github.com/Amir-Golmoradi/Customer-Management-System/internal/database"
 	model "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
 	"github.com/Amir-Golmoradi/Customer-Management-System/internal/handler"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/middleware"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/pwned"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/server"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/webhook"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	preflightTimeout = 10 * time.Second
+	shutdownTimeout  = 10 * time.Second
 )
 
 func main() {
-	ctx := context.Background()
+	checkSchema := flag.Bool("check-schema", false, "verify the database schema matches the generated queries and exit")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatal("Config error", err)
 	}
+	slog.SetLogLoggerLevel(cfg.LogLevel)
+	if cfg.StartupBanner {
+		log.Printf("Customer Management System starting on %s %s with %s storage\nEffective configuration:\n%s", cfg.ListenNetwork, cfg.ListenAddr, cfg.StorageBackend, cfg)
+	}
+	go reloadOnSIGHUP(ctx, cfg)
+
+	// pool stays nil with the memory storage backend
+	var pool *pgxpool.Pool
+	if cfg.StorageBackend == config.StorageMemory {
+		if *checkSchema {
+			log.Fatal("-check-schema needs STORAGE_BACKEND=postgres")
+		}
+		log.Println("MEMORY STORAGE: customers are kept in memory and lost on restart")
+	} else {
+		// Create pgx connection pool
+		pool, err = database.NewConnectionPool(ctx, cfg.DatabaseURL, int32(cfg.MinConns), cfg.DBStatementCacheMode, cfg.DBMaxConnLifetimeJitter)
+		if err != nil {
+			// Preflight never runs without a pool, so explain a bad config here
+			if configErr := cfg.Validate(); configErr != nil {
+				log.Fatal("Config error:\n", configErr)
+			}
+			log.Fatal("Config error", err)
+		}
+		defer pool.Close()
+
+		if *checkSchema {
+			err := database.CheckSchema(ctx, pool)
+			pool.Close()
+			if err != nil {
+				log.Println(err)
+				os.Exit(1)
+			}
+			log.Println("Schema OK")
+			return
+		}
+	}
 
-	// Create pgx connection pool
-	pool, err := database.NewConnectionPool(ctx, cfg.DatabaseURL)
+	preflightCtx, cancelPreflight := context.WithTimeout(ctx, preflightTimeout)
+	err = server.Preflight(preflightCtx, cfg, pool)
+	cancelPreflight()
 	if err != nil {
-		log.Fatal("Config error", err)
+		log.Fatal("Preflight failed:\n", err)
+	}
+
+	if cfg.LowercaseEmailsOnStartup && pool != nil {
+		lowercaseEmails(ctx, pool)
+	}
+
+	if cfg.WarmupPool && pool != nil {
+		start := time.Now()
+		if err := database.WarmPool(ctx, pool, cfg.MinConns); err != nil {
+			log.Fatal("Pool warmup failed: ", err)
+		}
+		log.Printf("Warmed up %d database connections in %s", cfg.MinConns, time.Since(start))
+	}
+
+	var customerRepo *customer.Repository
+	if pool != nil {
+		db := database.NewTimeoutPool(pool, cfg.PoolAcquireTimeout)
+		customerRepo = customer.NewCustomerRepository(db, model.New(db))
+	} else {
+		customerRepo = customer.NewMemoryRepository(cfg.RequireUniqueName)
+	}
+
+	var events customer.EventPublisher
+	var webhooks *webhook.Dispatcher
+	if cfg.WebhookURL != "" {
+		webhooks = webhook.NewDispatcher(cfg.WebhookURL, cfg.WebhookSecret)
+		webhooks.Start()
+		events = webhooks
+	}
+
+	shutdownGuard := &middleware.ShutdownGuard{}
+	readiness := &server.Readiness{}
+	httpServer := &http.Server{
+		Addr:    cfg.ListenAddr,
+		Handler: shutdownGuard.Wrap(withOperationalRoutes(cfg, readiness, server.NewDiagnostics(pool), initializeHandler(cfg, customerRepo, events))),
+	}
+	listener, err := server.Listen(cfg.ListenNetwork, cfg.ListenAddr)
+	if err != nil {
+		log.Fatal("Listen error ", err)
 	}
-	defer pool.Close()
+	go func() {
+		log.Printf("Running on %s %s", cfg.ListenNetwork, cfg.ListenAddr)
+		if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Server error ", err)
+		}
+	}()
 
-	queries := model.New(pool)
+	<-ctx.Done()
+	log.Println("Shutting down")
+	shutdownGuard.Begin()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Println("Shutdown error", err)
+	}
+	// Handlers have finished, so no more events can be published
+	if webhooks != nil {
+		if err := webhooks.Stop(shutdownCtx); err != nil {
+			log.Println("Shutdown error", err)
+		}
+	}
+}
 
-	initializeHandler(queries)
+// lowercaseEmails normalizes stored emails to lower case. Rows that would
+// collide are only reported, so the server starts either way; a failure of
+// the task itself is fatal, as the operator asked for it to run.
+func lowercaseEmails(ctx context.Context, pool *pgxpool.Pool) {
+	normalized, conflicts, err := database.LowercaseEmails(ctx, pool)
+	if err != nil {
+		log.Fatal("Email normalization failed: ", err)
+	}
+	log.Printf("Email normalization: %d emails lowercased, %d conflicts", normalized, len(conflicts))
+	for _, conflict := range conflicts {
+		log.Printf("Email normalization: customers %v share email %s when lowercased; merge or fix them by hand", conflict.IDs, conflict.Email)
+	}
 }
 
-func initializeHandler(queries *model.Queries) {
+// reloadOnSIGHUP re-reads the configuration on every SIGHUP and applies the
+// log level. Other settings need a restart, so changes to them are reported
+// and ignored.
+func reloadOnSIGHUP(ctx context.Context, cfg *config.Config) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
 
-	customerRepo := customer.NewCustomerRepository(queries)
-	customerService := customer.NewService(customerRepo)
-	customerHandler := handler.NewHandler(customerService)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		}
+		next, err := config.Reload()
+		if err != nil {
+			log.Println("Config reload failed, keeping current settings:", err)
+			continue
+		}
+		for _, field := range cfg.ChangedFields(next) {
+			if field != "LogLevel" {
+				log.Printf("Config reload: %s changed but needs a restart; ignored", field)
+			}
+		}
+		if next.LogLevel != cfg.LogLevel {
+			slog.SetLogLoggerLevel(next.LogLevel)
+			log.Printf("Config reload: log level %s -> %s", cfg.LogLevel, next.LogLevel)
+			cfg.LogLevel = next.LogLevel
+		}
+	}
+}
 
+// withOperationalRoutes serves the readiness check and the admin endpoints
+// that drain this instance before a deploy or help diagnose it, and
+// everything else from api. They sit outside the API middleware, so load
+// balancer probes are not subject to content negotiation, timeouts or the
+// access log.
+func withOperationalRoutes(cfg *config.Config, readiness *server.Readiness, diagnostics *server.Diagnostics, api http.Handler) http.Handler {
+	requireAdmin := middleware.RequireAdminToken(cfg.AdminToken)
 	mux := http.NewServeMux()
-	mux.HandleFunc("/customers", customerHandler.CreateCustomer)
-	mux.HandleFunc("/customer", customerHandler.GetCustomers)
-	server := &http.Server{
-		Addr:    ":8080",
-		Handler: mux,
+	mux.HandleFunc("GET /readyz", readiness.Ready)
+	mux.Handle("POST /admin/drain", requireAdmin(http.HandlerFunc(readiness.Drain)))
+	mux.Handle("POST /admin/undrain", requireAdmin(http.HandlerFunc(readiness.Undrain)))
+	mux.Handle("GET /admin/debug/stats", requireAdmin(http.HandlerFunc(diagnostics.Stats)))
+	if cfg.AdminPprof {
+		// pprof.Index serves the profiles it finds under /debug/pprof/
+		profiles := http.NewServeMux()
+		profiles.HandleFunc("/debug/pprof/", pprof.Index)
+		profiles.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		profiles.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		profiles.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		profiles.HandleFunc("/debug/pprof/trace", pprof.Trace)
+		mux.Handle("/admin/debug/pprof/", requireAdmin(http.StripPrefix("/admin", profiles)))
+	}
+	mux.Handle("/", api)
+	return mux
+}
+
+func initializeHandler(cfg *config.Config, customerRepo *customer.Repository, events customer.EventPublisher) http.Handler {
+	var breachChecker customer.BreachChecker
+	if cfg.CheckBreachedPasswords {
+		breachChecker = pwned.NewClient()
+	}
+	var disposableDomains *customer.DisposableDomains
+	if cfg.BlockDisposableEmails {
+		var err error
+		disposableDomains, err = customer.LoadDisposableDomains(cfg.DisposableEmailDomainsPath)
+		if err != nil {
+			log.Fatal("Config error ", err)
+		}
+	}
+	customerService := customer.NewService(customerRepo, breachChecker, disposableDomains, clock.Real{}, events, cfg.StatsCacheTTL, customer.NameLimits{
+		MaxLength: cfg.NameMaxLength,
+		MaxBytes:  cfg.NameMaxBytes,
+	})
+	customerHandler := handler.NewHandler(customerService, cfg)
+
+	router := handler.NewRouter()
+	routed := make(map[string]bool)
+	for _, route := range customerHandler.Routes() {
+		pattern := route.Method + " " + route.Path
+		routed[pattern] = true
+		router.Handle(route.Method, route.Path, middleware.Timeout(cfg.RouteTimeout(pattern))(route.Handler))
+	}
+	for pattern := range cfg.RouteTimeouts {
+		if !routed[pattern] {
+			log.Fatalf("Config error: ROUTE_TIMEOUTS names %q, which is not a route", pattern)
+		}
+	}
+
+	var handler http.Handler = router
+	if cfg.TransactionPerRequest {
+		handler = middleware.Transaction(customerRepo)(handler)
+	}
+	handler = middleware.StripPassword(handler)
+	handler = middleware.RequireJSONAccept(handler)
+	if cfg.DuplicateEmailPolicy != string(customer.DuplicateEmailReject) {
+		log.Printf("DUPLICATE_EMAIL_POLICY=%s: creating a customer with a taken email returns that customer instead of 409", cfg.DuplicateEmailPolicy)
+	}
+	if cfg.ReadOnly {
+		log.Println("READ-ONLY MODE: create, update and delete requests will be rejected")
+		handler = middleware.ReadOnly(handler)
+	}
+	if len(cfg.CORSAllowedOrigins) > 0 {
+		handler = middleware.CORS(cfg.CORSAllowedOrigins, cfg.CORSMaxAge)(handler)
+	}
+	if cfg.MaxConcurrentRequests > 0 {
+		handler = middleware.ConcurrencyLimit(cfg.MaxConcurrentRequests)(handler)
+	}
+	if cfg.RateLimit > 0 {
+		handler = middleware.RateLimit(cfg.RateLimit, cfg.RateLimitBurst)(handler)
+	}
+	handler = middleware.AuthenticateCustomer(customerAuthenticator(customerService))(handler)
+	if cfg.BasePath != "" {
+		handler = middleware.StripBasePath(cfg.BasePath)(handler)
+	}
+	handler = middleware.AccessLog(cfg.AccessLogSampleRate, cfg.SlowRequestThreshold)(handler)
+	handler = middleware.RequestID(cfg.RequestIDHeader)(handler)
+	return middleware.RealIP(cfg.TrustProxyHeaders)(handler)
+}
+
+// customerAuthenticator adapts the service's API token check to the
+// middleware, translating the tokens it refuses into the middleware's errors
+func customerAuthenticator(service *customer.Service) middleware.CustomerAuthenticator {
+	return func(ctx context.Context, token string) (int32, error) {
+		id, err := service.AuthenticateAPIToken(ctx, token)
+		switch {
+		case errors.Is(err, customer.ErrInvalidAPIToken):
+			return 0, middleware.ErrInvalidToken
+		case errors.Is(err, customer.ErrCustomerInactive):
+			return 0, middleware.ErrCustomerForbidden
+		}
+		return id, err
 	}
-	log.Fatal("Running on port 8080 ", server.ListenAndServe())
 }
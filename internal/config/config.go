@@ -1,18 +1,216 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
 	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// minAdminTokenLength keeps ADMIN_TOKEN out of reach of guessing
+const minAdminTokenLength = 16
+
+// Storage backends accepted in STORAGE_BACKEND
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+)
+
+// Policies accepted in DUPLICATE_EMAIL_POLICY
+var duplicateEmailPolicies = []string{"reject", "update", "ignore"}
+
+// Networks accepted in LISTEN_NETWORK
+var listenNetworks = []string{"tcp", "unix"}
+
+// Modes accepted in DB_STATEMENT_CACHE_MODE
+var statementCacheModes = []string{"prepare", "describe", "disabled"}
+
+// headerName is the subset of HTTP header names accepted in REQUEST_ID_HEADER
+var headerName = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
 type Config struct {
+	// LogLevel is the minimum level logged; it is the only setting a SIGHUP
+	// reload applies without a restart
+	LogLevel slog.Level
+
+	// StartupBanner logs the effective configuration once at startup, with
+	// secrets redacted
+	StartupBanner bool
+
+	// ListenNetwork is tcp, or unix to serve on a Unix domain socket for a
+	// sidecar or local proxy; ListenAddr is the TCP address or socket path
+	ListenNetwork string
+	ListenAddr    string
+
+	// StorageBackend is StoragePostgres, or StorageMemory to keep customers in
+	// process memory for demos; memory data is lost on restart
+	StorageBackend string
+
 	DatabaseURL string
 	DBHost      string
 	DBPort      string
 	DBName      string
 	DBUser      string
 	DBPassword  string
+
+	// DBRequireSSL refuses database connections that could be unencrypted:
+	// sslmode=require is added to a DATABASE_URL without an sslmode, and an
+	// explicit disable, allow or prefer fails validation
+	DBRequireSSL bool
+
+	// MinConns keeps at least this many idle connections in the pool; zero
+	// leaves the pool default. WarmupPool opens them before serving traffic.
+	MinConns   int
+	WarmupPool bool
+	// PoolAcquireTimeout bounds how long a query waits for a free pool
+	// connection; requests that wait longer get 503. Zero waits as long as the
+	// request does.
+	PoolAcquireTimeout time.Duration
+	// DBStatementCacheMode is how pgx caches statements: prepare (the pgx
+	// default), describe or disabled; empty keeps the DATABASE_URL setting.
+	// Behind PgBouncer in transaction or statement pooling mode prepared
+	// statements break, as consecutive queries may reach different server
+	// connections, so use describe or disabled there.
+	DBStatementCacheMode string
+	// DBMaxConnLifetimeJitter adds a random delay of up to this long to the
+	// lifetime of each pool connection; zero keeps the DATABASE_URL setting.
+	// Connections opened together, at startup or after a database restart,
+	// otherwise all expire together, and with many of them the pool then
+	// reconnects in one burst that stalls queries and floods the server.
+	DBMaxConnLifetimeJitter time.Duration
+
+	// BasePath is the public prefix the API is served under, e.g.
+	// "/api/customers", when a reverse proxy routes a subpath to this service
+	BasePath string
+
+	// TrustProxyHeaders derives the client IP from X-Forwarded-For/X-Real-IP.
+	// Only enable it when the service is reachable exclusively through a proxy.
+	TrustProxyHeaders bool
+
+	// TransactionPerRequest runs each create, update and delete request in a
+	// single database transaction that commits only on a 2xx response. Each
+	// such request holds a pool connection, or with the memory backend the
+	// whole store, until it finishes.
+	TransactionPerRequest bool
+
+	// MaxConcurrentRequests caps the requests handled at once; extra requests
+	// get 503. Zero means no limit.
+	MaxConcurrentRequests int
+
+	// RateLimit is how many requests per second each client may make on
+	// average, with bursts of up to RateLimitBurst; zero disables the limit.
+	// A client is the authenticated customer when there is one, and the
+	// client IP otherwise.
+	RateLimit      float64
+	RateLimitBurst int
+
+	// ReadOnly rejects create, update and delete requests while reads keep working
+	ReadOnly bool
+
+	// DefaultPageSize and MaxPageSize bound list responses
+	DefaultPageSize int
+	MaxPageSize     int
+	// DefaultSort orders list responses when the request has no ?sort
+	DefaultSort string
+	// StatsCacheTTL is how long GET /customers/stats serves a result from
+	// memory; zero queries the database on every request
+	StatsCacheTTL time.Duration
+	// MaxBatchSize caps the items one batch request may carry, such as the
+	// ids of a bulk update; larger batches get 400 before any database work
+	MaxBatchSize int
+
+	// NameMaxLength caps customer names in characters and NameMaxBytes in
+	// UTF-8 bytes, the unit column limits are measured in; zero disables
+	// either cap
+	NameMaxLength int
+	NameMaxBytes  int
+
+	// CheckBreachedPasswords rejects passwords found in the Pwned Passwords database
+	CheckBreachedPasswords bool
+
+	// BlockDisposableEmails rejects emails from throwaway providers, using the
+	// embedded domain list or the file at DisposableEmailDomainsPath when set
+	BlockDisposableEmails      bool
+	DisposableEmailDomainsPath string
+
+	// DeleteReturnsRecord answers DELETE with 200 and the deleted customer
+	// instead of an empty 204
+	DeleteReturnsRecord bool
+
+	// RequireUniqueName rejects duplicate customer names. It needs migration
+	// 0003, which fails on existing duplicates until they are cleaned up.
+	RequireUniqueName bool
+
+	// LowercaseEmailsOnStartup rewrites stored emails in lower case before
+	// serving, reporting rows that would collide instead of changing them.
+	// It is idempotent, so it can stay on; the memory backend ignores it.
+	LowercaseEmailsOnStartup bool
+
+	// RequireIfMatch rejects PATCH /customers/{id} requests that carry no
+	// If-Match header with 428, preventing blind overwrites
+	RequireIfMatch bool
+
+	// CORSAllowedOrigins lists browser origins allowed to call the API; empty disables CORS
+	CORSAllowedOrigins []string
+	// CORSMaxAge is how long browsers may cache preflight results; zero disables caching
+	CORSMaxAge time.Duration
+
+	// PrettyJSON indents every JSON response; meant for development only
+	PrettyJSON bool
+
+	// AccessLogSampleRate is the fraction (0.0–1.0) of successful requests
+	// written to the access log; error responses are always logged
+	AccessLogSampleRate float64
+	// SlowRequestThreshold logs any request taking longer at warn level,
+	// regardless of sampling; zero disables the warning
+	SlowRequestThreshold time.Duration
+	// RequestIDHeader names the header a correlation ID is read from and
+	// echoed in, so IDs set by a gateway or an upstream service carry through
+	RequestIDHeader string
+
+	// CreateDebounceWindow makes an identical create (same email, name and
+	// client IP) within this window return the first customer instead of a
+	// 409; zero disables it
+	CreateDebounceWindow time.Duration
+	// DuplicateEmailPolicy decides what POST /customers does with an email a
+	// customer already has: "reject" answers 409, "update" overwrites that
+	// customer, password included, and "ignore" returns it unchanged, both
+	// with 200. The last two let any caller read or take over an account by
+	// its email, so they are only for deployments where every caller is a
+	// trusted system, such as a sync job.
+	DuplicateEmailPolicy string
+
+	// WebhookURL receives a signed POST for every customer created, updated or
+	// deleted; empty disables webhooks. WebhookSecret keys the signature.
+	WebhookURL    string
+	WebhookSecret string
+
+	// EmailVerification issues an email verification token, valid for
+	// EmailVerificationTTL, with every new customer. RequireVerifiedEmail
+	// refuses logins until the customer has verified their email.
+	EmailVerification    bool
+	EmailVerificationTTL time.Duration
+	RequireVerifiedEmail bool
+
+	// RequestTimeout is the deadline given to each request; zero means none.
+	// RouteTimeouts overrides it for routes keyed by "METHOD /path" as listed
+	// in GET /docs, for endpoints that legitimately run longer.
+	RequestTimeout time.Duration
+	RouteTimeouts  map[string]time.Duration
+
+	// AdminToken is the bearer token for the /admin endpoints; empty
+	// disables them. AdminPprof also serves the net/http/pprof profiles
+	// under /admin/debug/pprof/.
+	AdminToken string
+	AdminPprof bool
 }
 
 // Since i don't want to read the memory address of each field
@@ -23,13 +221,345 @@ func Load() (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	return fromEnv()
+}
+
+// Reload reads the settings again for a running server. Values in .env now
+// override the process environment, since the file is the only source that
+// can have changed.
+func Reload() (*Config, error) {
+	if err := godotenv.Overload(); err != nil {
+		return nil, err
+	}
+	return fromEnv()
+}
+
+func fromEnv() (*Config, error) {
+	var env envParser
+	cfg := &Config{
+		LogLevel: env.level("LOG_LEVEL", slog.LevelInfo),
+
+		StartupBanner: env.bool("STARTUP_BANNER", true),
+
+		ListenNetwork: env.string("LISTEN_NETWORK", "tcp"),
+		ListenAddr:    env.string("LISTEN_ADDR", ":8080"),
+
+		StorageBackend: env.string("STORAGE_BACKEND", StoragePostgres),
+
+		PoolAcquireTimeout:      env.duration("DB_POOL_ACQUIRE_TIMEOUT", 5*time.Second),
+		DBStatementCacheMode:    env.string("DB_STATEMENT_CACHE_MODE", ""),
+		DBMaxConnLifetimeJitter: env.duration("DB_MAX_CONN_LIFETIME_JITTER", 0),
+
+		DBRequireSSL: env.bool("DB_REQUIRE_SSL", false),
+
+		DatabaseURL:       os.Getenv("DATABASE_URL"),
+		DBHost:            os.Getenv("DB_HOST"),
+		DBPort:            os.Getenv("DB_PORT"),
+		DBName:            os.Getenv("DB_NAME"),
+		DBUser:            os.Getenv("DB_USER"),
+		DBPassword:        os.Getenv("DB_PASSWORD"),
+		MinConns:          env.int("DB_MIN_CONNS", 0),
+		WarmupPool:        env.bool("DB_WARMUP_POOL", false),
+		TrustProxyHeaders: env.bool("TRUST_PROXY_HEADERS", false),
+		ReadOnly:          env.bool("READ_ONLY", false),
+		DefaultPageSize:   env.int("DEFAULT_PAGE_SIZE", 20),
+		MaxPageSize:       env.int("MAX_PAGE_SIZE", 100),
+		DefaultSort:       env.string("DEFAULT_SORT", "id"),
+
+		MaxBatchSize: env.int("MAX_BATCH_SIZE", 1000),
+
+		NameMaxLength: env.int("NAME_MAX_LENGTH", 100),
+		NameMaxBytes:  env.int("NAME_MAX_BYTES", 255),
+
+		StatsCacheTTL: env.duration("STATS_CACHE_TTL", 30*time.Second),
 
-	return &Config{
-		DatabaseURL: os.Getenv("DATABASE_URL"),
-		DBHost:      os.Getenv("DB_HOST"),
-		DBPort:      os.Getenv("DB_PORT"),
-		DBName:      os.Getenv("DB_NAME"),
-		DBUser:      os.Getenv("DB_USER"),
-		DBPassword:  os.Getenv("DB_PASSWORD"),
-	}, nil
+		CheckBreachedPasswords:   env.bool("CHECK_BREACHED_PASSWORDS", false),
+		DeleteReturnsRecord:      env.bool("DELETE_RETURNS_RECORD", true),
+		LowercaseEmailsOnStartup: env.bool("LOWERCASE_EMAILS_ON_STARTUP", false),
+		RequireUniqueName:        env.bool("REQUIRE_UNIQUE_NAME", false),
+		RequireIfMatch:           env.bool("REQUIRE_IF_MATCH", false),
+
+		BlockDisposableEmails:      env.bool("BLOCK_DISPOSABLE_EMAILS", false),
+		DisposableEmailDomainsPath: env.string("DISPOSABLE_EMAIL_DOMAINS_PATH", ""),
+
+		TransactionPerRequest: env.bool("TRANSACTION_PER_REQUEST", false),
+
+		MaxConcurrentRequests: env.int("MAX_CONCURRENT_REQUESTS", 0),
+		RateLimit:             env.float("RATE_LIMIT", 0),
+		RateLimitBurst:        env.int("RATE_LIMIT_BURST", 20),
+		BasePath:              env.string("BASE_PATH", ""),
+
+		CORSAllowedOrigins: env.list("CORS_ALLOWED_ORIGINS"),
+		CORSMaxAge:         env.duration("CORS_MAX_AGE", 10*time.Minute),
+
+		PrettyJSON:          env.bool("PRETTY_JSON", false),
+		AccessLogSampleRate: env.float("ACCESS_LOG_SAMPLE_RATE", 1.0),
+
+		SlowRequestThreshold: env.duration("SLOW_REQUEST_THRESHOLD", time.Second),
+		RequestIDHeader:      env.string("REQUEST_ID_HEADER", "X-Request-ID"),
+		CreateDebounceWindow: env.duration("CREATE_DEBOUNCE_WINDOW", 0),
+		DuplicateEmailPolicy: env.string("DUPLICATE_EMAIL_POLICY", "reject"),
+
+		WebhookURL:    env.string("WEBHOOK_URL", ""),
+		WebhookSecret: os.Getenv("WEBHOOK_SECRET"),
+
+		EmailVerification:    env.bool("EMAIL_VERIFICATION", false),
+		EmailVerificationTTL: env.duration("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+		RequireVerifiedEmail: env.bool("REQUIRE_VERIFIED_EMAIL", false),
+
+		RequestTimeout: env.duration("REQUEST_TIMEOUT", 30*time.Second),
+		RouteTimeouts: env.durationMap("ROUTE_TIMEOUTS", map[string]time.Duration{
+			"GET /customers/export":       0,
+			"POST /customers/bulk-update": 5 * time.Minute,
+			// Every row is bcrypt-hashed before anything is stored, tens of
+			// milliseconds each, so a full batch outlasts REQUEST_TIMEOUT
+			"POST /customers/import": 5 * time.Minute,
+		}),
+
+		AdminToken: os.Getenv("ADMIN_TOKEN"),
+		AdminPprof: env.bool("ADMIN_PPROF", false),
+	}
+	if env.err != nil {
+		return nil, env.err
+	}
+	if cfg.DBRequireSSL {
+		cfg.DatabaseURL = withRequiredSSL(cfg.DatabaseURL)
+	}
+	return cfg, nil
+}
+
+// Validate reports every setting that is missing, or parseable but unusable,
+// joined into one error
+func (c *Config) Validate() error {
+	var errs []error
+	if !slices.Contains(listenNetworks, c.ListenNetwork) {
+		errs = append(errs, errors.New("config: LISTEN_NETWORK must be tcp or unix"))
+	} else if c.ListenAddr == "" {
+		errs = append(errs, errors.New("config: LISTEN_ADDR is required"))
+	}
+	switch c.StorageBackend {
+	case StorageMemory:
+	case StoragePostgres:
+		if c.DatabaseURL == "" {
+			errs = append(errs, errors.New("config: DATABASE_URL is required"))
+		} else if err := validateDatabaseURL(c.DatabaseURL); err != nil {
+			errs = append(errs, err)
+		} else if c.DBRequireSSL {
+			if err := validateDatabaseSSL(c.DatabaseURL); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	default:
+		errs = append(errs, fmt.Errorf("config: STORAGE_BACKEND must be %q or %q", StoragePostgres, StorageMemory))
+	}
+	if c.MinConns < 0 {
+		errs = append(errs, errors.New("config: DB_MIN_CONNS must not be negative"))
+	} else if c.WarmupPool && c.MinConns == 0 {
+		errs = append(errs, errors.New("config: DB_WARMUP_POOL requires DB_MIN_CONNS"))
+	}
+	if c.DBStatementCacheMode != "" && !slices.Contains(statementCacheModes, c.DBStatementCacheMode) {
+		errs = append(errs, errors.New("config: DB_STATEMENT_CACHE_MODE must be prepare, describe or disabled"))
+	}
+	if c.DBMaxConnLifetimeJitter < 0 {
+		errs = append(errs, errors.New("config: DB_MAX_CONN_LIFETIME_JITTER must not be negative"))
+	}
+	if c.PoolAcquireTimeout < 0 {
+		errs = append(errs, errors.New("config: DB_POOL_ACQUIRE_TIMEOUT must not be negative"))
+	}
+	if c.DefaultPageSize < 1 || c.MaxPageSize < 1 {
+		errs = append(errs, errors.New("config: DEFAULT_PAGE_SIZE and MAX_PAGE_SIZE must be positive"))
+	} else if c.DefaultPageSize > c.MaxPageSize {
+		errs = append(errs, fmt.Errorf("config: DEFAULT_PAGE_SIZE (%d) exceeds MAX_PAGE_SIZE (%d)", c.DefaultPageSize, c.MaxPageSize))
+	}
+	if c.StatsCacheTTL < 0 {
+		errs = append(errs, errors.New("config: STATS_CACHE_TTL must not be negative"))
+	}
+	if c.MaxBatchSize < 1 {
+		errs = append(errs, errors.New("config: MAX_BATCH_SIZE must be positive"))
+	}
+	if c.NameMaxLength < 0 || c.NameMaxBytes < 0 {
+		errs = append(errs, errors.New("config: NAME_MAX_LENGTH and NAME_MAX_BYTES must not be negative"))
+	}
+	if c.MaxConcurrentRequests < 0 {
+		errs = append(errs, errors.New("config: MAX_CONCURRENT_REQUESTS must not be negative"))
+	}
+	if c.RateLimit < 0 {
+		errs = append(errs, errors.New("config: RATE_LIMIT must not be negative"))
+	} else if c.RateLimit > 0 && c.RateLimitBurst < 1 {
+		errs = append(errs, errors.New("config: RATE_LIMIT_BURST must be positive when RATE_LIMIT is set"))
+	}
+	if c.BasePath != "" && (!strings.HasPrefix(c.BasePath, "/") || strings.HasSuffix(c.BasePath, "/")) {
+		errs = append(errs, errors.New("config: BASE_PATH must start with / and not end with /"))
+	}
+	if c.CORSMaxAge < 0 {
+		errs = append(errs, errors.New("config: CORS_MAX_AGE must not be negative"))
+	}
+	if c.AccessLogSampleRate < 0 || c.AccessLogSampleRate > 1 {
+		errs = append(errs, errors.New("config: ACCESS_LOG_SAMPLE_RATE must be between 0 and 1"))
+	}
+	if !headerName.MatchString(c.RequestIDHeader) {
+		errs = append(errs, errors.New("config: REQUEST_ID_HEADER must be a header name such as X-Request-ID"))
+	}
+	if c.CreateDebounceWindow < 0 {
+		errs = append(errs, errors.New("config: CREATE_DEBOUNCE_WINDOW must not be negative"))
+	}
+	if !slices.Contains(duplicateEmailPolicies, c.DuplicateEmailPolicy) {
+		errs = append(errs, errors.New("config: DUPLICATE_EMAIL_POLICY must be reject, update or ignore"))
+	}
+	if c.WebhookURL != "" {
+		if u, err := url.Parse(c.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, errors.New("config: WEBHOOK_URL must be an absolute http or https URL"))
+		}
+		if c.WebhookSecret == "" {
+			errs = append(errs, errors.New("config: WEBHOOK_SECRET is required when WEBHOOK_URL is set"))
+		}
+	}
+	if c.EmailVerificationTTL <= 0 {
+		errs = append(errs, errors.New("config: EMAIL_VERIFICATION_TTL must be positive"))
+	}
+	if c.RequestTimeout < 0 {
+		errs = append(errs, errors.New("config: REQUEST_TIMEOUT must not be negative"))
+	}
+	for route, timeout := range c.RouteTimeouts {
+		if timeout < 0 {
+			errs = append(errs, fmt.Errorf("config: ROUTE_TIMEOUTS for %q must not be negative", route))
+		}
+	}
+	if c.AdminToken != "" && len(c.AdminToken) < minAdminTokenLength {
+		errs = append(errs, fmt.Errorf("config: ADMIN_TOKEN must be at least %d characters", minAdminTokenLength))
+	}
+	if c.SlowRequestThreshold < 0 {
+		errs = append(errs, errors.New("config: SLOW_REQUEST_THRESHOLD must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// envParser reads typed environment variables, keeping the first parse error
+// so Load can report it once after every field has been read
+type envParser struct {
+	err error
+}
+
+func (p *envParser) string(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func (p *envParser) bool(key string, fallback bool) bool {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		p.fail(key, err)
+		return fallback
+	}
+	return b
+}
+
+func (p *envParser) int(key string, fallback int) int {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		p.fail(key, err)
+		return fallback
+	}
+	return n
+}
+
+func (p *envParser) float(key string, fallback float64) float64 {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		p.fail(key, err)
+		return fallback
+	}
+	return f
+}
+
+func (p *envParser) duration(key string, fallback time.Duration) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		p.fail(key, err)
+		return fallback
+	}
+	return d
+}
+
+// level parses a slog level name such as "debug", "info", "warn" or "error"
+func (p *envParser) level(key string, fallback slog.Level) slog.Level {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		p.fail(key, err)
+		return fallback
+	}
+	return level
+}
+
+// durationMap parses comma-separated key=duration pairs such as
+// "GET /customers/export=10m,POST /customers/bulk-update=2m". A set variable
+// replaces fallback entirely.
+func (p *envParser) durationMap(key string, fallback map[string]time.Duration) map[string]time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	m := make(map[string]time.Duration)
+	for _, item := range p.list(key) {
+		k, v, found := strings.Cut(item, "=")
+		if !found {
+			p.fail(key, fmt.Errorf("%q is not key=duration", item))
+			return fallback
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil {
+			p.fail(key, err)
+			return fallback
+		}
+		m[strings.TrimSpace(k)] = d
+	}
+	return m
+}
+
+// RouteTimeout returns the deadline for the route with pattern, such as
+// "GET /customers/export": its RouteTimeouts entry, or RequestTimeout
+func (c *Config) RouteTimeout(pattern string) time.Duration {
+	if timeout, ok := c.RouteTimeouts[pattern]; ok {
+		return timeout
+	}
+	return c.RequestTimeout
+}
+
+// list splits a comma-separated variable, dropping empty entries
+func (p *envParser) list(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (p *envParser) fail(key string, err error) {
+	if p.err == nil {
+		p.err = fmt.Errorf("config %s: %w", key, err)
+	}
 }
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// validateDatabaseURL turns a malformed DATABASE_URL into an actionable error.
+// Passwords are redacted from every message since it ends up in logs.
+func validateDatabaseURL(raw string) error {
+	if scheme, _, ok := strings.Cut(raw, "://"); ok {
+		if scheme != "postgres" && scheme != "postgresql" {
+			return fmt.Errorf("config: invalid DATABASE_URL: unsupported scheme %q, use postgres://", scheme)
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			// url.Error repeats the raw URL, password included, so drop it
+			var urlErr *url.Error
+			if errors.As(err, &urlErr) {
+				err = urlErr.Err
+			}
+			return fmt.Errorf("config: invalid DATABASE_URL: %v", err)
+		}
+		if u.Hostname() == "" && u.Query().Get("host") == "" {
+			return fmt.Errorf("config: invalid DATABASE_URL %s: missing host", u.Redacted())
+		}
+	}
+	// pgx redacts the password in its own parse errors
+	if _, err := pgxpool.ParseConfig(raw); err != nil {
+		return fmt.Errorf("config: invalid DATABASE_URL: %w", err)
+	}
+	return nil
+}
+
+// dsnPassword matches the password of a keyword/value connection string,
+// quoted or not
+var dsnPassword = regexp.MustCompile(`password\s*=\s*('(?:[^'\\]|\\.)*'|\S+)`)
+
+// redactDatabaseURL masks the password in a DATABASE_URL given either as a
+// URL, in its user info or ?password, or as keyword/value pairs
+func redactDatabaseURL(raw string) string {
+	if !strings.Contains(raw, "://") {
+		return dsnPassword.ReplaceAllString(raw, "password="+redacted)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		// An unparseable URL may still hold a password, so hide all of it
+		return redacted
+	}
+	if query := u.Query(); query.Has("password") {
+		query.Set("password", redacted)
+		u.RawQuery = query.Encode()
+	}
+	return u.Redacted()
+}
+
+// secureSSLModes are the sslmode values that never fall back to plaintext
+var secureSSLModes = []string{"require", "verify-ca", "verify-full"}
+
+// dsnSSLMode matches the sslmode of a keyword/value connection string
+var dsnSSLMode = regexp.MustCompile(`sslmode\s*=\s*'?([\w-]+)`)
+
+// databaseSSLMode returns the sslmode set in a DATABASE_URL, falling back to
+// PGSSLMODE as pgx does; empty means neither sets one
+func databaseSSLMode(raw string) string {
+	var mode string
+	if strings.Contains(raw, "://") {
+		if u, err := url.Parse(raw); err == nil {
+			mode = u.Query().Get("sslmode")
+		}
+	} else if m := dsnSSLMode.FindStringSubmatch(raw); m != nil {
+		mode = m[1]
+	}
+	if mode == "" {
+		mode = os.Getenv("PGSSLMODE")
+	}
+	return mode
+}
+
+// withRequiredSSL adds sslmode=require to a DATABASE_URL that sets no
+// sslmode. An explicit mode is left alone for validateDatabaseSSL to judge.
+func withRequiredSSL(raw string) string {
+	if raw == "" || databaseSSLMode(raw) != "" {
+		return raw
+	}
+	if !strings.Contains(raw, "://") {
+		return raw + " sslmode=require"
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		// validateDatabaseURL reports it
+		return raw
+	}
+	query := u.Query()
+	query.Set("sslmode", "require")
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// validateDatabaseSSL rejects a DATABASE_URL whose sslmode allows an
+// unencrypted connection. The URL is never part of the message.
+func validateDatabaseSSL(raw string) error {
+	mode := databaseSSLMode(raw)
+	if !slices.Contains(secureSSLModes, mode) {
+		return fmt.Errorf("config: DB_REQUIRE_SSL is set but DATABASE_URL uses sslmode=%s; use require, verify-ca or verify-full", mode)
+	}
+	return nil
+}
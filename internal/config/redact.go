@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// redacted replaces secrets in logged settings; it matches what
+// url.URL.Redacted puts in place of a password
+const redacted = "xxxxx"
+
+// Redacted returns a copy of c that is safe to log: the database password,
+// the webhook secret, the admin token and any credentials embedded in URLs
+// are masked. Empty secrets stay empty so a missing setting is still visible.
+func (c *Config) Redacted() *Config {
+	safe := *c
+	safe.CORSAllowedOrigins = slices.Clone(c.CORSAllowedOrigins)
+	safe.RouteTimeouts = maps.Clone(c.RouteTimeouts)
+	if safe.DatabaseURL != "" {
+		safe.DatabaseURL = redactDatabaseURL(safe.DatabaseURL)
+	}
+	if safe.DBPassword != "" {
+		safe.DBPassword = redacted
+	}
+	if safe.WebhookSecret != "" {
+		safe.WebhookSecret = redacted
+	}
+	if safe.AdminToken != "" {
+		safe.AdminToken = redacted
+	}
+	if u, err := url.Parse(safe.WebhookURL); err == nil {
+		safe.WebhookURL = u.Redacted()
+	} else {
+		safe.WebhookURL = redacted
+	}
+	return &safe
+}
+
+// String lists every setting, one "Name: value" per line, with secrets
+// redacted, so printing a Config can never leak them
+func (c *Config) String() string {
+	safe := reflect.ValueOf(c.Redacted()).Elem()
+	var b strings.Builder
+	for i := range safe.NumField() {
+		fmt.Fprintf(&b, "  %s: %v\n", safe.Type().Field(i).Name, safe.Field(i).Interface())
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,15 @@
+package config
+
+import "reflect"
+
+// ChangedFields lists the Config fields whose value differs between c and next
+func (c *Config) ChangedFields(next *Config) []string {
+	var changed []string
+	before, after := reflect.ValueOf(c).Elem(), reflect.ValueOf(next).Elem()
+	for i := range before.NumField() {
+		if !reflect.DeepEqual(before.Field(i).Interface(), after.Field(i).Interface()) {
+			changed = append(changed, before.Type().Field(i).Name)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,94 @@
+// Package ctxkeys holds the typed keys for values stored in a request context.
+// Each key has its own unexported type so no other package can collide with it.
+package ctxkeys
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type requestIDKey struct{}
+
+type clientIPKey struct{}
+
+type txKey struct{}
+
+type customerIDKey struct{}
+
+type afterCommitKey struct{}
+
+type afterRollbackKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID stored in ctx, or an empty string
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithClientIP returns a copy of ctx carrying the client IP
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIP returns the client IP stored in ctx, or an empty string
+func ClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
+
+// WithTx returns a copy of ctx carrying the transaction a request runs in
+func WithTx(ctx context.Context, tx pgx.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// Tx returns the transaction stored in ctx, or nil
+func Tx(ctx context.Context) pgx.Tx {
+	tx, _ := ctx.Value(txKey{}).(pgx.Tx)
+	return tx
+}
+
+// WithAfterCommit returns a copy of ctx carrying the queue of work that
+// must wait until the request transaction commits, such as publishing events
+func WithAfterCommit(ctx context.Context, queue *[]func()) context.Context {
+	return context.WithValue(ctx, afterCommitKey{}, queue)
+}
+
+// AfterCommit returns the after-commit queue stored in ctx, or nil when the
+// request runs outside a transaction and work can happen right away
+func AfterCommit(ctx context.Context) *[]func() {
+	queue, _ := ctx.Value(afterCommitKey{}).(*[]func())
+	return queue
+}
+
+// WithAfterRollback returns a copy of ctx carrying the queue of work to run
+// when the request transaction rolls back instead, such as releasing what
+// was held for a commit that never came
+func WithAfterRollback(ctx context.Context, queue *[]func()) context.Context {
+	return context.WithValue(ctx, afterRollbackKey{}, queue)
+}
+
+// AfterRollback returns the after-rollback queue stored in ctx, or nil when
+// the request runs outside a transaction
+func AfterRollback(ctx context.Context) *[]func() {
+	queue, _ := ctx.Value(afterRollbackKey{}).(*[]func())
+	return queue
+}
+
+// WithCustomerID returns a copy of ctx carrying the ID of the customer the
+// request is authenticated as
+func WithCustomerID(ctx context.Context, id int32) context.Context {
+	return context.WithValue(ctx, customerIDKey{}, id)
+}
+
+// CustomerID returns the authenticated customer ID stored in ctx, and false
+// when the request is not authenticated as a customer
+func CustomerID(ctx context.Context) (int32, bool) {
+	id, ok := ctx.Value(customerIDKey{}).(int32)
+	return id, ok
+}
@@ -0,0 +1,58 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// maxSignupDays caps the days one signups series may span
+const maxSignupDays = 366
+
+var (
+	ErrInvalidDateRange  = errors.New("from must not be after to")
+	ErrDateRangeTooLarge = fmt.Errorf("date range must not span more than %d days", maxSignupDays)
+)
+
+// DailySignups is the number of customers created on one UTC day
+type DailySignups struct {
+	Date  time.Time
+	Count int64
+}
+
+// CustomerSignupsByDay returns one entry per day from the day of from to the
+// day of to, both included, counting the customers created that day. Days
+// without signups are reported with a zero count so a chart needs no gaps
+// filled. Timestamps are bucketed by UTC day.
+func (s *Service) CustomerSignupsByDay(ctx context.Context, from, to time.Time) ([]DailySignups, error) {
+	from, to = utcDay(from), utcDay(to)
+	if from.After(to) {
+		return nil, ErrInvalidDateRange
+	}
+	days := int(to.Sub(from)/(24*time.Hour)) + 1
+	if days > maxSignupDays {
+		return nil, ErrDateRangeTooLarge
+	}
+
+	rows, err := s.repository.CountSignupsByDay(ctx, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("customer signups by day: %w", err)
+	}
+	counts := make(map[time.Time]int64, len(rows))
+	for _, row := range rows {
+		counts[utcDay(row.Day.Time)] = row.Signups
+	}
+	series := make([]DailySignups, days)
+	for i := range series {
+		day := from.AddDate(0, 0, i)
+		series[i] = DailySignups{Date: day, Count: counts[day]}
+	}
+	return series, nil
+}
+
+// utcDay returns midnight UTC of the day t falls on in UTC
+func utcDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,54 @@
+package customer
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+
+	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// anonymizedName replaces the name of an anonymized customer
+const anonymizedName = "deleted"
+
+// anonymizedEmail is a unique, undeliverable placeholder; the .invalid TLD is
+// reserved and never resolves
+func anonymizedEmail(id int32) string {
+	return fmt.Sprintf("deleted+%d@invalid", id)
+}
+
+// AnonymizeCustomer erases a customer's personal data for right-to-erasure
+// requests. The row is kept, with placeholder name and email, an unusable
+// password and no phone or avatar, and is marked deleted so related records
+// and audit history stay intact. The customer's API token is revoked.
+func (s *Service) AnonymizeCustomer(ctx context.Context, id int32) (*database.Customer, error) {
+	// Hash random bytes so the stored value is a well-formed hash nobody knows
+	// the password for
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("anonymize customer: %w", err)
+	}
+	hash, err := bcrypt.GenerateFromPassword(secret, bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("anonymize customer: %w", err)
+	}
+
+	var anonymized *database.Customer
+	err = s.repository.RunInTx(ctx, func(tx Store) error {
+		var err error
+		anonymized, err = tx.AnonymizeCustomer(ctx, id, anonymizedName, anonymizedEmail(id), string(hash))
+		if err != nil {
+			return err
+		}
+		if err := tx.DeleteAPIToken(ctx, id); err != nil {
+			return err
+		}
+		return tx.DeleteEmailVerification(ctx, id)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("anonymize customer: %w", err)
+	}
+	s.publish(ctx, EventDeleted, id)
+	return anonymized, nil
+}
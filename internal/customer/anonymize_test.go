@@ -0,0 +1,86 @@
+package customer_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/clock"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestAnonymizeCustomerScrubsPIIAndKeepsTheRow(t *testing.T) {
+	ctx := context.Background()
+	repo := customer.NewMemoryRepository(false)
+	service := customer.NewService(repo, nil, nil, clock.Real{}, nil, 0, customer.NameLimits{})
+	const password = "correct-horse-battery"
+	original, err := service.RegisterCustomer(ctx, customer.RegisterInput{
+		Name:      "Jane Doe",
+		Email:     "jane@example.com",
+		Password:  password,
+		Phone:     "+14155550100",
+		AvatarURL: "https://example.com/jane.png",
+	})
+	if err != nil {
+		t.Fatalf("RegisterCustomer: %v", err)
+	}
+	if _, err := service.AddTags(ctx, original.ID, []string{"vip"}); err != nil {
+		t.Fatalf("AddTags: %v", err)
+	}
+	token, err := service.RotateAPIToken(ctx, original.ID)
+	if err != nil {
+		t.Fatalf("RotateAPIToken: %v", err)
+	}
+
+	c, err := service.AnonymizeCustomer(ctx, original.ID)
+	if err != nil {
+		t.Fatalf("AnonymizeCustomer: %v", err)
+	}
+
+	// PII is gone
+	if c.Name != "deleted" {
+		t.Errorf("name = %q, want deleted", c.Name)
+	}
+	if want := fmt.Sprintf("deleted+%d@invalid", original.ID); c.Email != want {
+		t.Errorf("email = %q, want %q", c.Email, want)
+	}
+	if c.Phone.Valid || c.AvatarUrl.Valid || c.LastLoginAt.Valid {
+		t.Errorf("phone %v, avatar %v, last login %v, want all cleared", c.Phone, c.AvatarUrl, c.LastLoginAt)
+	}
+	if c.Password == original.Password || bcrypt.CompareHashAndPassword([]byte(c.Password), []byte(password)) == nil {
+		t.Error("the old password still matches the stored hash")
+	}
+	if c.IsActive || c.EmailVerified {
+		t.Errorf("active %v, verified %v, want both false", c.IsActive, c.EmailVerified)
+	}
+	if _, err := service.AuthenticateAPIToken(ctx, token); !errors.Is(err, customer.ErrInvalidAPIToken) {
+		t.Errorf("AuthenticateAPIToken after anonymizing: err = %v, want ErrInvalidAPIToken", err)
+	}
+
+	// The row stays, marked deleted, with its identity and related records
+	if c.ID != original.ID || !c.CreatedAt.Time.Equal(original.CreatedAt.Time) {
+		t.Errorf("anonymized row is %d created %v, want %d created %v", c.ID, c.CreatedAt.Time, original.ID, original.CreatedAt.Time)
+	}
+	if !c.DeletedAt.Valid {
+		t.Error("anonymized customer is not marked deleted")
+	}
+	if _, err := service.GetCustomerByID(ctx, original.ID); !errors.Is(err, customer.ErrCustomerNotFound) {
+		t.Errorf("GetCustomerByID after anonymizing: err = %v, want ErrCustomerNotFound", err)
+	}
+	assertTags(t, repo, original.ID, "vip")
+	again, err := service.AnonymizeCustomer(ctx, original.ID)
+	if err != nil || again.ID != original.ID {
+		t.Fatalf("anonymizing again = %v, %v, want the same row", again, err)
+	}
+
+	// The email is free for a new customer
+	newer, err := service.RegisterCustomer(ctx, customer.RegisterInput{Name: "Jane Doe", Email: "jane@example.com", Password: password})
+	if err != nil {
+		t.Fatalf("RegisterCustomer with the anonymized email: %v", err)
+	}
+	if newer.ID == original.ID {
+		t.Error("new registration reused the anonymized row")
+	}
+}
@@ -0,0 +1,54 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrCustomerInactive   = errors.New("customer is deactivated")
+)
+
+// dummyPasswordHash is compared against when the email is unknown, so an
+// unknown email takes as long to reject as a wrong password
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("not a real password"), bcrypt.DefaultCost)
+	return hash
+})
+
+// Authenticate checks a customer's email and password and records the login.
+// Unknown emails and wrong passwords both return ErrInvalidCredentials; a
+// deactivated customer with the right password gets ErrCustomerInactive, and
+// with requireVerifiedEmail an unverified one gets ErrEmailNotVerified. The
+// returned customer still carries the previous last_login_at.
+func (s *Service) Authenticate(ctx context.Context, email, password string, requireVerifiedEmail bool) (*database.Customer, error) {
+	c, err := s.repository.FindCustomerByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if errors.Is(err, ErrCustomerNotFound) {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, fmt.Errorf("authenticate: %w", err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(c.Password), []byte(password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if !c.IsActive {
+		return nil, ErrCustomerInactive
+	}
+	if requireVerifiedEmail && !c.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	if err := s.repository.UpdateLastLogin(ctx, c.ID); err != nil {
+		return nil, fmt.Errorf("authenticate: %w", err)
+	}
+	return c, nil
+}
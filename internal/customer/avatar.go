@@ -0,0 +1,29 @@
+package customer
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// maxAvatarURLLength keeps stored URLs within what browsers reliably accept
+const maxAvatarURLLength = 2048
+
+var ErrInvalidAvatarURL = errors.New("avatar_url must be an absolute http or https URL of at most 2048 characters")
+
+// ValidateAvatarURL validates an optional avatar URL. An empty string means
+// no avatar and is returned unchanged.
+func ValidateAvatarURL(avatarURL string) (string, error) {
+	avatarURL = strings.TrimSpace(avatarURL)
+	if avatarURL == "" {
+		return "", nil
+	}
+	if len(avatarURL) > maxAvatarURLLength {
+		return "", ErrInvalidAvatarURL
+	}
+	u, err := url.Parse(avatarURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil {
+		return "", ErrInvalidAvatarURL
+	}
+	return avatarURL, nil
+}
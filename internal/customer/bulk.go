@@ -0,0 +1,70 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrBulkFieldNotAllowed = errors.New("field cannot be bulk updated")
+	ErrBulkInvalidValue    = errors.New("invalid value for bulk update field")
+)
+
+// BulkItemResult is the outcome for one customer of a best-effort bulk
+// update; Err is nil when the customer was updated
+type BulkItemResult struct {
+	ID  int32
+	Err error
+}
+
+// BulkUpdateField sets one allowlisted field to value on every customer in ids
+// with a single statement and returns how many customers were updated; each
+// of them gets an update event, as with BulkUpdateFieldEach
+func (s *Service) BulkUpdateField(ctx context.Context, ids []int32, field string, value any) (int64, error) {
+	active, err := bulkActiveValue(field, value)
+	if err != nil {
+		return 0, err
+	}
+	updated, err := s.repository.SetCustomersActive(ctx, ids, active)
+	if err != nil {
+		return 0, fmt.Errorf("bulk update customers: %w", err)
+	}
+	for _, id := range updated {
+		s.publish(ctx, EventUpdated, id)
+	}
+	return int64(len(updated)), nil
+}
+
+// BulkUpdateFieldEach updates the customers in ids one by one, so a failure
+// only affects its own customer, and reports the outcome for each in order.
+// The returned error is only set when field or value is invalid.
+func (s *Service) BulkUpdateFieldEach(ctx context.Context, ids []int32, field string, value any) ([]BulkItemResult, error) {
+	active, err := bulkActiveValue(field, value)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]BulkItemResult, len(ids))
+	for i, id := range ids {
+		results[i].ID = id
+		if _, err := s.repository.SetCustomerActive(ctx, id, active); err != nil {
+			results[i].Err = fmt.Errorf("bulk update customer %d: %w", id, err)
+			continue
+		}
+		s.publish(ctx, EventUpdated, id)
+	}
+	return results, nil
+}
+
+// bulkActiveValue validates a bulk update; is_active is the only field that
+// can be bulk updated so far
+func bulkActiveValue(field string, value any) (bool, error) {
+	if field != "is_active" {
+		return false, ErrBulkFieldNotAllowed
+	}
+	active, ok := value.(bool)
+	if !ok {
+		return false, ErrBulkInvalidValue
+	}
+	return active, nil
+}
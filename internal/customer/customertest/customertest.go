@@ -0,0 +1,63 @@
+// Package customertest provides helpers for integration tests that run the
+// customer repository against a real Postgres database.
+//
+// Each test gets a repository bound to its own transaction, which is rolled
+// back when the test ends, so tests never see each other's rows and no table
+// has to be truncated:
+//
+//	func TestCreateCustomer(t *testing.T) {
+//		repo := customertest.NewRepository(t, customertest.OpenPool(t))
+//		c, err := repo.CreateNewCustomer(ctx, "Jane", "jane@example.com", "hash", "", "")
+//		...
+//	}
+//
+// RunInTx on that repository opens a savepoint inside the test transaction,
+// so service code that uses transactions is rolled back as well.
+// TestNewRepository walks through the whole cycle.
+package customertest
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DatabaseURLEnv names the variable holding the test database URL
+const DatabaseURLEnv = "TEST_DATABASE_URL"
+
+// OpenPool connects to the database in TEST_DATABASE_URL, skipping the test
+// when it is unset. The pool is closed when the test ends.
+func OpenPool(t testing.TB) *pgxpool.Pool {
+	t.Helper()
+	url := os.Getenv(DatabaseURLEnv)
+	if url == "" {
+		t.Skipf("%s is not set", DatabaseURLEnv)
+	}
+	pool, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		t.Fatalf("connect to test database: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// NewRepository begins a transaction on pool and returns a repository bound
+// to it. The transaction is rolled back when the test ends.
+func NewRepository(t testing.TB, pool *pgxpool.Pool) *customer.Repository {
+	t.Helper()
+	ctx := context.Background()
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("begin test transaction: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := tx.Rollback(ctx); err != nil {
+			t.Errorf("roll back test transaction: %v", err)
+		}
+	})
+	return customer.NewCustomerRepository(tx, database.New(tx))
+}
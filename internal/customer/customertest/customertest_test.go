@@ -0,0 +1,57 @@
+package customertest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer/customertest"
+	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+)
+
+var errAbort = errors.New("abort")
+
+// TestNewRepository shows the begin, use, roll back cycle: rows written
+// through the repository are visible inside the test and gone after it
+func TestNewRepository(t *testing.T) {
+	pool := customertest.OpenPool(t)
+	ctx := context.Background()
+	email := fmt.Sprintf("customertest-%d@example.com", time.Now().UnixNano())
+	nested := "nested-" + email
+
+	t.Run("use", func(t *testing.T) {
+		repo := customertest.NewRepository(t, pool)
+		if _, err := repo.CreateNewCustomer(ctx, "Jane", email, "hash", "", ""); err != nil {
+			t.Fatalf("create customer: %v", err)
+		}
+		if _, err := repo.FindCustomerByEmail(ctx, email); err != nil {
+			t.Fatalf("find customer inside the test: %v", err)
+		}
+
+		// RunInTx nests as a savepoint, so its rollback keeps the rest
+		err := repo.RunInTx(ctx, func(tx customer.Store) error {
+			if _, err := tx.CreateNewCustomer(ctx, "Nested", nested, "hash", "", ""); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Fatalf("RunInTx error = %v, want %v", err, errAbort)
+		}
+		if _, err := repo.FindCustomerByEmail(ctx, nested); !errors.Is(err, customer.ErrCustomerNotFound) {
+			t.Fatalf("customer from a rolled back savepoint: err = %v, want ErrCustomerNotFound", err)
+		}
+		if _, err := repo.FindCustomerByEmail(ctx, email); err != nil {
+			t.Fatalf("customer lost with the savepoint: %v", err)
+		}
+	})
+
+	// The subtest's transaction was rolled back when it ended
+	repo := customer.NewCustomerRepository(pool, database.New(pool))
+	if _, err := repo.FindCustomerByEmail(ctx, email); !errors.Is(err, customer.ErrCustomerNotFound) {
+		t.Fatalf("customer after the test: err = %v, want ErrCustomerNotFound", err)
+	}
+}
@@ -0,0 +1,75 @@
+package customer
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var ErrDisposableEmail = errors.New("disposable email addresses are not allowed")
+
+//go:embed disposable_domains.txt
+var embeddedDisposableDomains string
+
+// DisposableDomains is a set of email domains used for throwaway signups
+type DisposableDomains struct {
+	domains map[string]struct{}
+}
+
+// LoadDisposableDomains reads the domain list at path, or the embedded list
+// when path is empty. The file holds one domain per line; blank lines and
+// lines starting with # are ignored.
+func LoadDisposableDomains(path string) (*DisposableDomains, error) {
+	if path == "" {
+		return parseDisposableDomains(strings.NewReader(embeddedDisposableDomains))
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("load disposable domains: %w", err)
+	}
+	defer f.Close()
+	d, err := parseDisposableDomains(f)
+	if err != nil {
+		return nil, fmt.Errorf("load disposable domains: %w", err)
+	}
+	return d, nil
+}
+
+func parseDisposableDomains(r io.Reader) (*DisposableDomains, error) {
+	d := &DisposableDomains{domains: make(map[string]struct{})}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		d.domains[line] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Contains reports whether email's domain, or any parent of it, is listed
+func (d *DisposableDomains) Contains(email string) bool {
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+	for {
+		if _, ok := d.domains[domain]; ok {
+			return true
+		}
+		dot := strings.IndexByte(domain, '.')
+		if dot < 0 {
+			return false
+		}
+		domain = domain[dot+1:]
+	}
+}
@@ -0,0 +1,46 @@
+package customer
+
+import (
+	"context"
+	"time"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/ctxkeys"
+)
+
+// EventType names a customer lifecycle change
+type EventType string
+
+const (
+	EventCreated EventType = "customer.created"
+	EventUpdated EventType = "customer.updated"
+	EventDeleted EventType = "customer.deleted"
+)
+
+// Event describes a committed change to a customer. It carries no customer
+// data beyond the ID, so nothing sensitive leaves through it.
+type Event struct {
+	Type       EventType
+	CustomerID int32
+	OccurredAt time.Time
+}
+
+// EventPublisher receives lifecycle events. Publish is called on the request
+// path and must not block.
+type EventPublisher interface {
+	Publish(Event)
+}
+
+// publish reports a change to the configured publisher, if any. Inside a
+// request transaction the event waits for the commit, so a change that is
+// rolled back is never announced.
+func (s *Service) publish(ctx context.Context, eventType EventType, id int32) {
+	if s.events == nil {
+		return
+	}
+	event := Event{Type: eventType, CustomerID: id, OccurredAt: s.clock.Now()}
+	if queue := ctxkeys.AfterCommit(ctx); queue != nil {
+		*queue = append(*queue, func() { s.events.Publish(event) })
+		return
+	}
+	s.events.Publish(event)
+}
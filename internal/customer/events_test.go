@@ -0,0 +1,145 @@
+package customer_test
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/clock"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+)
+
+// recordedEvents is an EventPublisher that keeps what it is given
+type recordedEvents struct {
+	mu     sync.Mutex
+	events []customer.Event
+}
+
+func (r *recordedEvents) Publish(e customer.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+// take returns the events published since the last call
+func (r *recordedEvents) take() []publishedEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var taken []publishedEvent
+	for _, e := range r.events {
+		taken = append(taken, publishedEvent{e.Type, e.CustomerID})
+	}
+	r.events = nil
+	return taken
+}
+
+type publishedEvent struct {
+	Type customer.EventType
+	ID   int32
+}
+
+// TestServiceMutationsPublishEvents checks that every service method that
+// changes a customer announces it, one event per customer changed
+func TestServiceMutationsPublishEvents(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// call changes customers a and b and returns the events it should
+		// publish
+		call func(t *testing.T, s *customer.Service, a, b int32) []publishedEvent
+	}{
+		{"BulkUpdateField", func(t *testing.T, s *customer.Service, a, b int32) []publishedEvent {
+			n, err := s.BulkUpdateField(ctx, []int32{a, b, missingID}, "is_active", false)
+			if err != nil || n != 2 {
+				t.Fatalf("BulkUpdateField = %d, %v, want 2", n, err)
+			}
+			return []publishedEvent{{customer.EventUpdated, a}, {customer.EventUpdated, b}}
+		}},
+		{"BulkUpdateFieldEach", func(t *testing.T, s *customer.Service, a, b int32) []publishedEvent {
+			if _, err := s.BulkUpdateFieldEach(ctx, []int32{a, b, missingID}, "is_active", false); err != nil {
+				t.Fatalf("BulkUpdateFieldEach: %v", err)
+			}
+			return []publishedEvent{{customer.EventUpdated, a}, {customer.EventUpdated, b}}
+		}},
+		{"DeleteCustomerByEmail", func(t *testing.T, s *customer.Service, a, b int32) []publishedEvent {
+			c, err := s.GetCustomerByID(ctx, a)
+			if err != nil {
+				t.Fatalf("GetCustomerByID: %v", err)
+			}
+			if err := s.DeleteCustomerByEmail(ctx, c.Email); err != nil {
+				t.Fatalf("DeleteCustomerByEmail: %v", err)
+			}
+			return []publishedEvent{{customer.EventDeleted, a}}
+		}},
+		{"DeleteCustomerByID", func(t *testing.T, s *customer.Service, a, b int32) []publishedEvent {
+			if _, err := s.DeleteCustomerByID(ctx, a); err != nil {
+				t.Fatalf("DeleteCustomerByID: %v", err)
+			}
+			return []publishedEvent{{customer.EventDeleted, a}}
+		}},
+		{"SetCustomerActive", func(t *testing.T, s *customer.Service, a, b int32) []publishedEvent {
+			if _, err := s.SetCustomerActive(ctx, a, false); err != nil {
+				t.Fatalf("SetCustomerActive: %v", err)
+			}
+			return []publishedEvent{{customer.EventUpdated, a}}
+		}},
+		{"UpdateCustomerName", func(t *testing.T, s *customer.Service, a, b int32) []publishedEvent {
+			if _, err := s.UpdateCustomerName(ctx, a, "Renamed Customer"); err != nil {
+				t.Fatalf("UpdateCustomerName: %v", err)
+			}
+			return []publishedEvent{{customer.EventUpdated, a}}
+		}},
+		{"TouchCustomer", func(t *testing.T, s *customer.Service, a, b int32) []publishedEvent {
+			if _, err := s.TouchCustomer(ctx, a); err != nil {
+				t.Fatalf("TouchCustomer: %v", err)
+			}
+			return []publishedEvent{{customer.EventUpdated, a}}
+		}},
+		{"AddTags and RemoveTags", func(t *testing.T, s *customer.Service, a, b int32) []publishedEvent {
+			if _, err := s.AddTags(ctx, a, []string{"vip"}); err != nil {
+				t.Fatalf("AddTags: %v", err)
+			}
+			if _, err := s.RemoveTags(ctx, a, []string{"vip"}); err != nil {
+				t.Fatalf("RemoveTags: %v", err)
+			}
+			return []publishedEvent{{customer.EventUpdated, a}, {customer.EventUpdated, a}}
+		}},
+		{"AnonymizeCustomer", func(t *testing.T, s *customer.Service, a, b int32) []publishedEvent {
+			if _, err := s.AnonymizeCustomer(ctx, a); err != nil {
+				t.Fatalf("AnonymizeCustomer: %v", err)
+			}
+			return []publishedEvent{{customer.EventDeleted, a}}
+		}},
+		{"MergeCustomers", func(t *testing.T, s *customer.Service, a, b int32) []publishedEvent {
+			if _, err := s.MergeCustomers(ctx, a, b); err != nil {
+				t.Fatalf("MergeCustomers: %v", err)
+			}
+			return []publishedEvent{{customer.EventUpdated, a}, {customer.EventDeleted, b}}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := customer.NewMemoryRepository(false)
+			events := &recordedEvents{}
+			service := customer.NewService(repo, nil, nil, clock.Real{}, events, 0, customer.NameLimits{})
+			a, b := createCustomer(t, repo), createCustomer(t, repo)
+
+			want := tt.call(t, service, a.ID, b.ID)
+			got := events.take()
+			sortEvents(got)
+			sortEvents(want)
+			if !slices.Equal(got, want) {
+				t.Errorf("published %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func sortEvents(events []publishedEvent) {
+	slices.SortFunc(events, func(x, y publishedEvent) int {
+		return cmp.Or(cmp.Compare(x.ID, y.ID), cmp.Compare(x.Type, y.Type))
+	})
+}
@@ -0,0 +1,57 @@
+package customer
+
+import (
+	"errors"
+
+	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+)
+
+// ListFilter selects which customers a list request returns and in what
+// order. New filters are added here so the list signatures stay unchanged.
+type ListFilter struct {
+	// Active limits the list to one status; nil returns customers of any status
+	Active *bool
+	// Tag limits the list to customers carrying this tag; empty means any
+	Tag    string
+	Sort   ListSort
+	Limit  int32
+	Offset int32
+}
+
+// Validate rejects a filter the list query cannot run
+func (f ListFilter) Validate() error {
+	if _, err := ParseListSort(string(f.Sort)); err != nil {
+		return err
+	}
+	if f.Tag != "" {
+		if _, err := normalizeTag(f.Tag); err != nil {
+			return err
+		}
+	}
+	if f.Limit < 1 {
+		return errors.New("limit must be positive")
+	}
+	if f.Offset < 0 {
+		return errors.New("offset must not be negative")
+	}
+	return nil
+}
+
+// listParams builds the arguments for the ListCustomers query
+func (f ListFilter) listParams() database.ListCustomersParams {
+	return database.ListCustomersParams{
+		IsActive: activeFilter(f.Active),
+		Tag:      tagFilter(f.Tag),
+		Sort:     string(f.Sort),
+		Limit:    f.Limit,
+		Offset:   f.Offset,
+	}
+}
+
+// countParams builds the arguments for the CountCustomers query
+func (f ListFilter) countParams() database.CountCustomersParams {
+	return database.CountCustomersParams{
+		IsActive: activeFilter(f.Active),
+		Tag:      tagFilter(f.Tag),
+	}
+}
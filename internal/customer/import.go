@@ -0,0 +1,115 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+)
+
+// ErrImportSkipped marks a row of an atomic import that was valid but not
+// stored, because another row failed and the whole import was rolled back
+var ErrImportSkipped = errors.New("not imported because another row failed")
+
+// ImportRow is one customer to import, with the line of the source file it
+// was read from so failures can point back at it
+type ImportRow struct {
+	Line int
+	RegisterInput
+}
+
+// ImportResult is the outcome of one ImportRow. Err is nil when the customer
+// was created, and otherwise a *ValidationError, a conflict such as
+// ErrEmailAlreadyExists, or ErrImportSkipped.
+type ImportResult struct {
+	Line     int
+	Customer *database.Customer
+	Err      error
+}
+
+// ImportCustomers registers every row, validated as RegisterCustomer does; a
+// taken email is always a failure, whatever the duplicate email policy.
+//
+// An atomic import stores all rows in one transaction or none of them: any
+// invalid row stops it before anything is written, and the first row the
+// database rejects rolls it back and marks the rows after it as skipped.
+// Otherwise each row is stored under its own savepoint and a failure only
+// affects its row, even inside a request transaction. The returned error is
+// only set when the import as a whole failed.
+func (s *Service) ImportCustomers(ctx context.Context, rows []ImportRow, atomic bool) ([]ImportResult, error) {
+	results := make([]ImportResult, len(rows))
+	regs := make([]registration, len(rows))
+	valid := true
+	for i, row := range rows {
+		results[i].Line = row.Line
+		reg, err := s.prepareRegistration(ctx, row.RegisterInput)
+		if err != nil {
+			results[i].Err = err
+			valid = false
+			continue
+		}
+		regs[i] = reg
+	}
+
+	if !atomic {
+		for i, reg := range regs {
+			if results[i].Err != nil {
+				continue
+			}
+			var c *database.Customer
+			err := s.repository.RunInTx(ctx, func(tx Store) error {
+				var err error
+				c, err = tx.CreateNewCustomer(ctx, reg.name, reg.email, reg.passwordHash, reg.phone, reg.avatarURL)
+				return err
+			})
+			if err != nil {
+				results[i].Err = fmt.Errorf("import customer: %w", err)
+				continue
+			}
+			results[i].Customer = c
+			s.publish(ctx, EventCreated, c.ID)
+		}
+		return results, nil
+	}
+
+	if !valid {
+		markSkipped(results)
+		return results, nil
+	}
+	var rejected bool
+	err := s.repository.RunInTx(ctx, func(tx Store) error {
+		for i, reg := range regs {
+			c, err := tx.CreateNewCustomer(ctx, reg.name, reg.email, reg.passwordHash, reg.phone, reg.avatarURL)
+			if err != nil {
+				results[i].Err = fmt.Errorf("import customer: %w", err)
+				rejected = true
+				return err
+			}
+			results[i].Customer = c
+		}
+		return nil
+	})
+	if rejected {
+		markSkipped(results)
+		return results, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("import customers: %w", err)
+	}
+	for _, result := range results {
+		s.publish(ctx, EventCreated, result.Customer.ID)
+	}
+	return results, nil
+}
+
+// markSkipped marks every row of a failed atomic import that has no error of
+// its own as skipped, dropping customers that were rolled back
+func markSkipped(results []ImportResult) {
+	for i := range results {
+		results[i].Customer = nil
+		if results[i].Err == nil {
+			results[i].Err = ErrImportSkipped
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+)
+
+// maxLookupEmails caps how many emails one lookup may resolve
+const maxLookupEmails = 100
+
+var (
+	ErrNoEmails      = errors.New("at least one email is required")
+	ErrTooManyEmails = fmt.Errorf("at most %d emails can be looked up at once", maxLookupEmails)
+	// ErrInvalidIdentifier is an identifier that is neither a customer ID
+	// nor an email address
+	ErrInvalidIdentifier = errors.New("identifier must be a customer id or an email address")
+)
+
+// GetCustomerByEmailOrID looks a customer up by an identifier that is either
+// a customer ID or an email. An identifier made only of digits is an ID, as
+// no email address is; anything else must be a valid email. Identifiers that
+// are neither, including IDs out of range, give ErrInvalidIdentifier.
+func (s *Service) GetCustomerByEmailOrID(ctx context.Context, identifier string) (*database.Customer, error) {
+	identifier = strings.TrimSpace(identifier)
+	if identifier != "" && strings.Trim(identifier, "0123456789") == "" {
+		id, err := strconv.ParseInt(identifier, 10, 32)
+		if err != nil || id == 0 {
+			return nil, ErrInvalidIdentifier
+		}
+		return s.GetCustomerByID(ctx, int32(id))
+	}
+	email, err := normalizeEmail(identifier)
+	if err != nil {
+		return nil, ErrInvalidIdentifier
+	}
+	return s.GetCustomerByEmail(ctx, email)
+}
+
+// LookupCustomersByEmails resolves many emails in one query. Emails are
+// matched case-insensitively; found customers are keyed by lowercased email
+// and the emails with no customer are returned in request order.
+func (s *Service) LookupCustomersByEmails(ctx context.Context, emails []string) (map[string]database.Customer, []string, error) {
+	if len(emails) == 0 {
+		return nil, nil, ErrNoEmails
+	}
+	if len(emails) > maxLookupEmails {
+		return nil, nil, ErrTooManyEmails
+	}
+	keys := make([]string, 0, len(emails))
+	seen := make(map[string]bool, len(emails))
+	for _, email := range emails {
+		key, err := normalizeEmail(email)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	found, err := s.repository.FindCustomersByEmails(ctx, keys)
+	if err != nil {
+		return nil, nil, fmt.Errorf("lookup customers by emails: %w", err)
+	}
+	var missing []string
+	for _, key := range keys {
+		if _, ok := found[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	return found, missing, nil
+}
@@ -0,0 +1,778 @@
+package customer
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// foreignKeyViolation is the Postgres error code for a foreign key violation
+const foreignKeyViolation = "23503"
+
+// NewMemoryRepository returns a repository that keeps customers in process
+// memory, for demos and for running without Postgres. It reports the same
+// errors as the database, so not-found and duplicate email or name map to the
+// same sentinels. uniqueNames mirrors the optional customers_name_key index.
+// Everything is lost when the process exits.
+func NewMemoryRepository(uniqueNames bool) *Repository {
+	store := &memoryStore{data: newMemoryData(), uniqueNames: uniqueNames}
+	return &Repository{db: store, queries: &memoryQueries{store: store}}
+}
+
+// memoryStore holds the data behind a memory repository. A transaction holds
+// mu from Begin until Commit or Rollback, so transactions run one at a time.
+type memoryStore struct {
+	mu          sync.Mutex
+	data        *memoryData
+	uniqueNames bool
+}
+
+type memoryData struct {
+	nextID    int32
+	customers map[int32]database.Customer
+	tags      map[int32]map[string]bool
+	tokens    map[int32]database.CustomerApiToken
+	// verifications is keyed by customer ID, like the table's primary key
+	verifications map[int32]database.CustomerEmailVerification
+}
+
+func newMemoryData() *memoryData {
+	return &memoryData{
+		customers: make(map[int32]database.Customer),
+		tags:      make(map[int32]map[string]bool),
+		tokens:    make(map[int32]database.CustomerApiToken),
+
+		verifications: make(map[int32]database.CustomerEmailVerification),
+	}
+}
+
+func (d *memoryData) clone() *memoryData {
+	c := &memoryData{
+		nextID:    d.nextID,
+		customers: make(map[int32]database.Customer, len(d.customers)),
+		tags:      make(map[int32]map[string]bool, len(d.tags)),
+		tokens:    make(map[int32]database.CustomerApiToken, len(d.tokens)),
+
+		verifications: make(map[int32]database.CustomerEmailVerification, len(d.verifications)),
+	}
+	for id, customer := range d.customers {
+		c.customers[id] = customer
+	}
+	for id, tags := range d.tags {
+		copied := make(map[string]bool, len(tags))
+		for tag := range tags {
+			copied[tag] = true
+		}
+		c.tags[id] = copied
+	}
+	for id, token := range d.tokens {
+		c.tokens[id] = token
+	}
+	for id, verification := range d.verifications {
+		c.verifications[id] = verification
+	}
+	return c
+}
+
+// Begin starts a transaction; it blocks until any other transaction ends
+func (s *memoryStore) Begin(ctx context.Context) (pgx.Tx, error) {
+	s.mu.Lock()
+	return &memoryTx{store: s, snapshot: s.data.clone(), outer: true}, nil
+}
+
+// memoryTx restores its snapshot on rollback. Only the methods RunInTx
+// uses are implemented; the embedded nil pgx.Tx panics on any other.
+type memoryTx struct {
+	pgx.Tx
+	store    *memoryStore
+	snapshot *memoryData
+	// outer is false for savepoints, which do not hold the store lock
+	outer bool
+	done  bool
+}
+
+func (tx *memoryTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	if tx.done {
+		return nil, pgx.ErrTxClosed
+	}
+	return &memoryTx{store: tx.store, snapshot: tx.store.data.clone()}, nil
+}
+
+func (tx *memoryTx) Commit(ctx context.Context) error {
+	return tx.end(false)
+}
+
+func (tx *memoryTx) Rollback(ctx context.Context) error {
+	return tx.end(true)
+}
+
+func (tx *memoryTx) end(rollback bool) error {
+	if tx.done {
+		return pgx.ErrTxClosed
+	}
+	tx.done = true
+	if rollback {
+		tx.store.data = tx.snapshot
+	}
+	if tx.outer {
+		tx.store.mu.Unlock()
+	}
+	return nil
+}
+
+// memoryQueries implements the generated queries against a memoryStore.
+// Inside a transaction the store lock is already held.
+type memoryQueries struct {
+	store *memoryStore
+	inTx  bool
+}
+
+var _ database.Querier = (*memoryQueries)(nil)
+
+// lock takes the store lock for one query outside a transaction and returns
+// the function releasing it
+func (q *memoryQueries) lock() func() {
+	if q.inTx {
+		return func() {}
+	}
+	q.store.mu.Lock()
+	return q.store.mu.Unlock
+}
+
+// memoryNow matches the precision of a Postgres timestamp
+func memoryNow() pgtype.Timestamp {
+	return pgtype.Timestamp{Time: time.Now().UTC().Truncate(time.Microsecond), Valid: true}
+}
+
+func uniqueViolationError(constraint string) error {
+	return &pgconn.PgError{Code: uniqueViolation, ConstraintName: constraint, Message: "duplicate key value violates unique constraint"}
+}
+
+func foreignKeyError() error {
+	return &pgconn.PgError{Code: foreignKeyViolation, Message: "insert or update violates foreign key constraint"}
+}
+
+// checkUnique reports the constraint c would violate if stored, ignoring
+// the row with c's own ID
+func (q *memoryQueries) checkUnique(c database.Customer) error {
+	for id, other := range q.store.data.customers {
+		if id == c.ID {
+			continue
+		}
+		if other.Email == c.Email {
+			return uniqueViolationError("customers_email_key")
+		}
+		if strings.EqualFold(other.Email, c.Email) {
+			return uniqueViolationError(emailLowerUniqueIndex)
+		}
+		if q.store.uniqueNames && other.Name == c.Name && !other.DeletedAt.Valid && !c.DeletedAt.Valid {
+			return uniqueViolationError(nameUniqueIndex)
+		}
+	}
+	return nil
+}
+
+// live returns the customer with id unless it is missing or soft-deleted
+func (q *memoryQueries) live(id int32) (database.Customer, bool) {
+	c, ok := q.store.data.customers[id]
+	return c, ok && !c.DeletedAt.Valid
+}
+
+// sorted returns the customers that match keep, ordered by ID
+func (q *memoryQueries) sorted(keep func(database.Customer) bool) []database.Customer {
+	var matched []database.Customer
+	for _, c := range q.store.data.customers {
+		if keep(c) {
+			matched = append(matched, c)
+		}
+	}
+	slices.SortFunc(matched, func(a, b database.Customer) int { return int(a.ID) - int(b.ID) })
+	return matched
+}
+
+func (q *memoryQueries) matchesFilter(c database.Customer, isActive pgtype.Bool, tag pgtype.Text) bool {
+	if c.DeletedAt.Valid {
+		return false
+	}
+	if isActive.Valid && c.IsActive != isActive.Bool {
+		return false
+	}
+	if tag.Valid && !q.store.data.tags[c.ID][tag.String] {
+		return false
+	}
+	return true
+}
+
+// ilike reports whether s matches a Postgres ILIKE pattern, where % matches
+// any run of characters, _ matches one and a backslash escapes the next
+func ilike(s, pattern string) bool {
+	var expr strings.Builder
+	expr.WriteString("(?is)^")
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			expr.WriteString(".*")
+		case r == '_':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String()).MatchString(s)
+}
+
+func (q *memoryQueries) matchesSearch(c database.Customer, pattern string) bool {
+	return !c.DeletedAt.Valid && (ilike(c.Name, pattern) || ilike(c.Email, pattern))
+}
+
+func (q *memoryQueries) AddCustomerTag(ctx context.Context, arg database.AddCustomerTagParams) error {
+	defer q.lock()()
+	if _, ok := q.store.data.customers[arg.CustomerID]; !ok {
+		return foreignKeyError()
+	}
+	if q.store.data.tags[arg.CustomerID] == nil {
+		q.store.data.tags[arg.CustomerID] = make(map[string]bool)
+	}
+	q.store.data.tags[arg.CustomerID][arg.Tag] = true
+	return nil
+}
+
+func (q *memoryQueries) AnonymizeCustomer(ctx context.Context, arg database.AnonymizeCustomerParams) (database.Customer, error) {
+	defer q.lock()()
+	c, ok := q.store.data.customers[arg.ID]
+	if !ok {
+		return database.Customer{}, pgx.ErrNoRows
+	}
+	now := memoryNow()
+	c.Name = arg.Name
+	c.Email = arg.Email
+	c.Password = arg.Password
+	c.Phone = pgtype.Text{}
+	c.AvatarUrl = pgtype.Text{}
+	c.LastLoginAt = pgtype.Timestamp{}
+	c.EmailVerified = false
+	c.IsActive = false
+	if !c.DeletedAt.Valid {
+		c.DeletedAt = now
+	}
+	c.UpdatedAt = now
+	if err := q.checkUnique(c); err != nil {
+		return database.Customer{}, err
+	}
+	q.store.data.customers[c.ID] = c
+	return c, nil
+}
+
+func (q *memoryQueries) CountCustomers(ctx context.Context, arg database.CountCustomersParams) (int64, error) {
+	defer q.lock()()
+	var count int64
+	for _, c := range q.store.data.customers {
+		if q.matchesFilter(c, arg.IsActive, arg.Tag) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (q *memoryQueries) CountSearchCustomers(ctx context.Context, pattern string) (int64, error) {
+	defer q.lock()()
+	var count int64
+	for _, c := range q.store.data.customers {
+		if q.matchesSearch(c, pattern) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (q *memoryQueries) CountSignupsByDay(ctx context.Context, arg database.CountSignupsByDayParams) ([]database.CountSignupsByDayRow, error) {
+	defer q.lock()()
+	counts := make(map[time.Time]int64)
+	for _, c := range q.store.data.customers {
+		if c.CreatedAt.Time.Before(arg.Since.Time) || !c.CreatedAt.Time.Before(arg.Until.Time) {
+			continue
+		}
+		counts[utcDay(c.CreatedAt.Time)]++
+	}
+	var rows []database.CountSignupsByDayRow
+	for day, signups := range counts {
+		rows = append(rows, database.CountSignupsByDayRow{Day: pgtype.Date{Time: day, Valid: true}, Signups: signups})
+	}
+	slices.SortFunc(rows, func(a, b database.CountSignupsByDayRow) int { return a.Day.Time.Compare(b.Day.Time) })
+	return rows, nil
+}
+
+func (q *memoryQueries) CreateCustomer(ctx context.Context, arg database.CreateCustomerParams) (database.Customer, error) {
+	defer q.lock()()
+	return q.insert(arg)
+}
+
+// insert adds a customer; the caller holds the lock
+func (q *memoryQueries) insert(arg database.CreateCustomerParams) (database.Customer, error) {
+	now := memoryNow()
+	c := database.Customer{
+		ID:        q.store.data.nextID + 1,
+		Name:      arg.Name,
+		Email:     arg.Email,
+		Password:  arg.Password,
+		CreatedAt: now,
+		UpdatedAt: now,
+		IsActive:  true,
+		Phone:     arg.Phone,
+		AvatarUrl: arg.AvatarUrl,
+	}
+	if err := q.checkUnique(c); err != nil {
+		return database.Customer{}, err
+	}
+	q.store.data.nextID = c.ID
+	q.store.data.customers[c.ID] = c
+	return c, nil
+}
+
+func (q *memoryQueries) DeleteCustomerAPIToken(ctx context.Context, customerID int32) error {
+	defer q.lock()()
+	delete(q.store.data.tokens, customerID)
+	return nil
+}
+
+func (q *memoryQueries) DeleteCustomerByEmail(ctx context.Context, email string) ([]int32, error) {
+	defer q.lock()()
+	var deleted []int32
+	for id, c := range q.store.data.customers {
+		if strings.EqualFold(c.Email, email) {
+			delete(q.store.data.customers, id)
+			delete(q.store.data.tags, id)
+			delete(q.store.data.tokens, id)
+			delete(q.store.data.verifications, id)
+			deleted = append(deleted, id)
+		}
+	}
+	return deleted, nil
+}
+
+func (q *memoryQueries) DeleteEmailVerification(ctx context.Context, customerID int32) error {
+	defer q.lock()()
+	delete(q.store.data.verifications, customerID)
+	return nil
+}
+
+func (q *memoryQueries) ExistsCustomerByID(ctx context.Context, id int32) (bool, error) {
+	defer q.lock()()
+	_, ok := q.live(id)
+	return ok, nil
+}
+
+func (q *memoryQueries) GetCustomerByEmail(ctx context.Context, email string) (database.Customer, error) {
+	defer q.lock()()
+	for _, c := range q.store.data.customers {
+		if strings.EqualFold(c.Email, email) && !c.DeletedAt.Valid {
+			return c, nil
+		}
+	}
+	return database.Customer{}, pgx.ErrNoRows
+}
+
+func (q *memoryQueries) GetCustomerByID(ctx context.Context, id int32) (database.Customer, error) {
+	defer q.lock()()
+	c, ok := q.live(id)
+	if !ok {
+		return database.Customer{}, pgx.ErrNoRows
+	}
+	return c, nil
+}
+
+// GetCustomerByIDForUpdate needs no row lock: transactions already run one
+// at a time
+func (q *memoryQueries) GetCustomerByIDForUpdate(ctx context.Context, id int32) (database.Customer, error) {
+	return q.GetCustomerByID(ctx, id)
+}
+
+func (q *memoryQueries) GetCustomerIDByAPITokenHash(ctx context.Context, tokenHash string) (database.GetCustomerIDByAPITokenHashRow, error) {
+	defer q.lock()()
+	for id, token := range q.store.data.tokens {
+		if c, ok := q.live(id); ok && token.TokenHash == tokenHash {
+			return database.GetCustomerIDByAPITokenHashRow{CustomerID: id, IsActive: c.IsActive}, nil
+		}
+	}
+	return database.GetCustomerIDByAPITokenHashRow{}, pgx.ErrNoRows
+}
+
+func (q *memoryQueries) GetCustomerStats(ctx context.Context) (database.GetCustomerStatsRow, error) {
+	defer q.lock()()
+	now := time.Now().UTC()
+	var stats database.GetCustomerStatsRow
+	for _, c := range q.store.data.customers {
+		if c.DeletedAt.Valid {
+			continue
+		}
+		stats.Total++
+		age := now.Sub(c.CreatedAt.Time)
+		if age <= 24*time.Hour {
+			stats.CreatedLast24h++
+		}
+		if age <= 7*24*time.Hour {
+			stats.CreatedLast7d++
+		}
+		if age <= 30*24*time.Hour {
+			stats.CreatedLast30d++
+		}
+		if c.IsActive {
+			stats.Active++
+		} else {
+			stats.Inactive++
+		}
+	}
+	return stats, nil
+}
+
+func (q *memoryQueries) GetEmailVerificationByTokenHash(ctx context.Context, tokenHash string) (database.CustomerEmailVerification, error) {
+	defer q.lock()()
+	for _, v := range q.store.data.verifications {
+		if v.TokenHash == tokenHash {
+			return v, nil
+		}
+	}
+	return database.CustomerEmailVerification{}, pgx.ErrNoRows
+}
+
+func (q *memoryQueries) ListCustomerTags(ctx context.Context, customerID int32) ([]string, error) {
+	defer q.lock()()
+	var tags []string
+	for tag := range q.store.data.tags[customerID] {
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+	return tags, nil
+}
+
+func (q *memoryQueries) ListCustomers(ctx context.Context, arg database.ListCustomersParams) ([]database.Customer, error) {
+	defer q.lock()()
+	customers := q.sorted(func(c database.Customer) bool {
+		return q.matchesFilter(c, arg.IsActive, arg.Tag)
+	})
+	switch arg.Sort {
+	case "name":
+		slices.SortStableFunc(customers, func(a, b database.Customer) int { return strings.Compare(a.Name, b.Name) })
+	case "created_at":
+		slices.SortStableFunc(customers, func(a, b database.Customer) int { return a.CreatedAt.Time.Compare(b.CreatedAt.Time) })
+	}
+	return page(customers, int(arg.Offset), int(arg.Limit)), nil
+}
+
+func (q *memoryQueries) ListCustomersAfterID(ctx context.Context, arg database.ListCustomersAfterIDParams) ([]database.Customer, error) {
+	defer q.lock()()
+	customers := q.sorted(func(c database.Customer) bool {
+		return !c.DeletedAt.Valid && c.ID > arg.ID
+	})
+	return page(customers, 0, int(arg.Limit)), nil
+}
+
+func (q *memoryQueries) ListCustomersByEmails(ctx context.Context, emails []string) ([]database.Customer, error) {
+	defer q.lock()()
+	return q.sorted(func(c database.Customer) bool {
+		return !c.DeletedAt.Valid && slices.Contains(emails, strings.ToLower(c.Email))
+	}), nil
+}
+
+// page applies OFFSET and LIMIT, returning nil for an empty page as the
+// generated queries do
+func page(customers []database.Customer, offset, limit int) []database.Customer {
+	if offset >= len(customers) {
+		return nil
+	}
+	customers = customers[offset:]
+	if limit < len(customers) {
+		customers = customers[:limit]
+	}
+	if len(customers) == 0 {
+		return nil
+	}
+	return customers
+}
+
+func (q *memoryQueries) MarkEmailVerified(ctx context.Context, arg database.MarkEmailVerifiedParams) (database.Customer, error) {
+	defer q.lock()()
+	c, ok := q.live(arg.ID)
+	if !ok || !strings.EqualFold(c.Email, arg.Email) {
+		return database.Customer{}, pgx.ErrNoRows
+	}
+	c.EmailVerified = true
+	c.UpdatedAt = memoryNow()
+	q.store.data.customers[c.ID] = c
+	return c, nil
+}
+
+func (q *memoryQueries) MoveCustomerTags(ctx context.Context, arg database.MoveCustomerTagsParams) error {
+	defer q.lock()()
+	moved := q.store.data.tags[arg.FromID]
+	if len(moved) == 0 {
+		return nil
+	}
+	if _, ok := q.store.data.customers[arg.ToID]; !ok {
+		return foreignKeyError()
+	}
+	delete(q.store.data.tags, arg.FromID)
+	if q.store.data.tags[arg.ToID] == nil {
+		q.store.data.tags[arg.ToID] = make(map[string]bool)
+	}
+	for tag := range moved {
+		q.store.data.tags[arg.ToID][tag] = true
+	}
+	return nil
+}
+
+func (q *memoryQueries) PatchCustomer(ctx context.Context, arg database.PatchCustomerParams) (database.Customer, error) {
+	defer q.lock()()
+	c, ok := q.live(arg.ID)
+	if !ok {
+		return database.Customer{}, pgx.ErrNoRows
+	}
+	if arg.ExpectedUpdatedAt.Valid && !c.UpdatedAt.Time.Equal(arg.ExpectedUpdatedAt.Time) {
+		return database.Customer{}, pgx.ErrNoRows
+	}
+	patched := c
+	if arg.Name.Valid {
+		patched.Name = arg.Name.String
+	}
+	if arg.Email.Valid {
+		patched.Email = arg.Email.String
+		patched.EmailVerified = c.EmailVerified && strings.EqualFold(c.Email, arg.Email.String)
+	}
+	if arg.Password.Valid {
+		patched.Password = arg.Password.String
+	}
+	patched.Phone = patchText(c.Phone, arg.Phone)
+	patched.AvatarUrl = patchText(c.AvatarUrl, arg.AvatarUrl)
+	// Like the IS DISTINCT FROM guard, an update that changes nothing
+	// matches no row
+	if patched == c {
+		return database.Customer{}, pgx.ErrNoRows
+	}
+	patched.UpdatedAt = memoryNow()
+	if err := q.checkUnique(patched); err != nil {
+		return database.Customer{}, err
+	}
+	q.store.data.customers[patched.ID] = patched
+	return patched, nil
+}
+
+// patchText applies a nullable patch value, where "" clears the column
+func patchText(current, patch pgtype.Text) pgtype.Text {
+	switch {
+	case !patch.Valid:
+		return current
+	case patch.String == "":
+		return pgtype.Text{}
+	default:
+		return patch
+	}
+}
+
+func (q *memoryQueries) RemoveCustomerTag(ctx context.Context, arg database.RemoveCustomerTagParams) error {
+	defer q.lock()()
+	delete(q.store.data.tags[arg.CustomerID], arg.Tag)
+	return nil
+}
+
+func (q *memoryQueries) SearchCustomers(ctx context.Context, arg database.SearchCustomersParams) ([]database.Customer, error) {
+	defer q.lock()()
+	customers := q.sorted(func(c database.Customer) bool {
+		return q.matchesSearch(c, arg.Pattern)
+	})
+	slices.SortStableFunc(customers, func(a, b database.Customer) int {
+		aExact := strings.EqualFold(a.Email, arg.Query)
+		bExact := strings.EqualFold(b.Email, arg.Query)
+		switch {
+		case aExact && !bExact:
+			return -1
+		case bExact && !aExact:
+			return 1
+		}
+		return 0
+	})
+	return page(customers, int(arg.Offset), int(arg.Limit)), nil
+}
+
+func (q *memoryQueries) SetCustomerAPIToken(ctx context.Context, arg database.SetCustomerAPITokenParams) error {
+	defer q.lock()()
+	if _, ok := q.store.data.customers[arg.CustomerID]; !ok {
+		return foreignKeyError()
+	}
+	q.store.data.tokens[arg.CustomerID] = database.CustomerApiToken{
+		CustomerID: arg.CustomerID,
+		TokenHash:  arg.TokenHash,
+		CreatedAt:  memoryNow(),
+	}
+	return nil
+}
+
+func (q *memoryQueries) SetCustomerActive(ctx context.Context, arg database.SetCustomerActiveParams) (database.Customer, error) {
+	defer q.lock()()
+	c, ok := q.live(arg.ID)
+	if !ok {
+		return database.Customer{}, pgx.ErrNoRows
+	}
+	c.IsActive = arg.IsActive
+	c.UpdatedAt = memoryNow()
+	q.store.data.customers[c.ID] = c
+	return c, nil
+}
+
+func (q *memoryQueries) SetCustomersActive(ctx context.Context, arg database.SetCustomersActiveParams) ([]int32, error) {
+	defer q.lock()()
+	now := memoryNow()
+	var updated []int32
+	for id, c := range q.store.data.customers {
+		if c.DeletedAt.Valid || !slices.Contains(arg.Ids, id) {
+			continue
+		}
+		c.IsActive = arg.IsActive
+		c.UpdatedAt = now
+		q.store.data.customers[id] = c
+		updated = append(updated, id)
+	}
+	return updated, nil
+}
+
+func (q *memoryQueries) SetEmailVerification(ctx context.Context, arg database.SetEmailVerificationParams) error {
+	defer q.lock()()
+	if _, ok := q.store.data.customers[arg.CustomerID]; !ok {
+		return foreignKeyError()
+	}
+	q.store.data.verifications[arg.CustomerID] = database.CustomerEmailVerification{
+		CustomerID: arg.CustomerID,
+		TokenHash:  arg.TokenHash,
+		ExpiresAt:  arg.ExpiresAt,
+		Email:      arg.Email,
+		CreatedAt:  memoryNow(),
+	}
+	return nil
+}
+
+func (q *memoryQueries) SoftDeleteCustomer(ctx context.Context, id int32) (database.Customer, error) {
+	defer q.lock()()
+	c, ok := q.live(id)
+	if !ok {
+		return database.Customer{}, pgx.ErrNoRows
+	}
+	now := memoryNow()
+	c.DeletedAt = now
+	c.UpdatedAt = now
+	q.store.data.customers[id] = c
+	return c, nil
+}
+
+func (q *memoryQueries) TouchCustomer(ctx context.Context, id int32) (pgtype.Timestamp, error) {
+	defer q.lock()()
+	c, ok := q.live(id)
+	if !ok {
+		return pgtype.Timestamp{}, pgx.ErrNoRows
+	}
+	c.UpdatedAt = memoryNow()
+	q.store.data.customers[id] = c
+	return c.UpdatedAt, nil
+}
+
+func (q *memoryQueries) UpdateCustomer(ctx context.Context, arg database.UpdateCustomerParams) (database.Customer, error) {
+	defer q.lock()()
+	c, ok := q.live(arg.ID)
+	if !ok {
+		return database.Customer{}, pgx.ErrNoRows
+	}
+	c.EmailVerified = c.EmailVerified && strings.EqualFold(c.Email, arg.Email)
+	c.Name = arg.Name
+	c.Email = arg.Email
+	c.Password = arg.Password
+	c.UpdatedAt = memoryNow()
+	if err := q.checkUnique(c); err != nil {
+		return database.Customer{}, err
+	}
+	q.store.data.customers[c.ID] = c
+	return c, nil
+}
+
+func (q *memoryQueries) UpdateCustomerName(ctx context.Context, arg database.UpdateCustomerNameParams) (database.Customer, error) {
+	defer q.lock()()
+	c, ok := q.live(arg.ID)
+	if !ok {
+		return database.Customer{}, pgx.ErrNoRows
+	}
+	c.Name = arg.Name
+	c.UpdatedAt = memoryNow()
+	if err := q.checkUnique(c); err != nil {
+		return database.Customer{}, err
+	}
+	q.store.data.customers[c.ID] = c
+	return c, nil
+}
+
+func (q *memoryQueries) UpdateLastLogin(ctx context.Context, id int32) error {
+	defer q.lock()()
+	c, ok := q.live(id)
+	if !ok {
+		return nil
+	}
+	c.LastLoginAt = memoryNow()
+	q.store.data.customers[id] = c
+	return nil
+}
+
+func (q *memoryQueries) UpsertCustomerByEmail(ctx context.Context, arg database.UpsertCustomerByEmailParams) (database.UpsertCustomerByEmailRow, error) {
+	defer q.lock()()
+	for _, c := range q.store.data.customers {
+		if !strings.EqualFold(c.Email, arg.Email) {
+			continue
+		}
+		if c.DeletedAt.Valid {
+			return database.UpsertCustomerByEmailRow{}, pgx.ErrNoRows
+		}
+		c.Name = arg.Name
+		c.Password = arg.Password
+		c.Phone = arg.Phone
+		c.AvatarUrl = arg.AvatarUrl
+		c.UpdatedAt = memoryNow()
+		if err := q.checkUnique(c); err != nil {
+			return database.UpsertCustomerByEmailRow{}, err
+		}
+		q.store.data.customers[c.ID] = c
+		return upsertRow(c, false), nil
+	}
+	c, err := q.insert(database.CreateCustomerParams(arg))
+	if err != nil {
+		return database.UpsertCustomerByEmailRow{}, err
+	}
+	return upsertRow(c, true), nil
+}
+
+func upsertRow(c database.Customer, inserted bool) database.UpsertCustomerByEmailRow {
+	return database.UpsertCustomerByEmailRow{
+		ID:            c.ID,
+		Name:          c.Name,
+		Email:         c.Email,
+		Password:      c.Password,
+		CreatedAt:     c.CreatedAt,
+		UpdatedAt:     c.UpdatedAt,
+		IsActive:      c.IsActive,
+		DeletedAt:     c.DeletedAt,
+		Phone:         c.Phone,
+		AvatarUrl:     c.AvatarUrl,
+		LastLoginAt:   c.LastLoginAt,
+		EmailVerified: c.EmailVerified,
+		Inserted:      inserted,
+	}
+}
@@ -0,0 +1,60 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+)
+
+var ErrMergeSameCustomer = errors.New("cannot merge a customer into itself")
+
+// MergeCustomers folds mergeID into keepID: related records move to the kept
+// customer and the merged one is soft-deleted, all in one transaction
+func (s *Service) MergeCustomers(ctx context.Context, keepID, mergeID int32) (*database.Customer, error) {
+	if keepID == mergeID {
+		return nil, ErrMergeSameCustomer
+	}
+
+	var kept *database.Customer
+	err := s.repository.RunInTx(ctx, func(tx Store) error {
+		// Lock both rows in ID order so two merges of the same pair in
+		// opposite directions cannot deadlock
+		for _, id := range []int32{min(keepID, mergeID), max(keepID, mergeID)} {
+			c, err := tx.GetCustomerByIDForUpdate(ctx, id)
+			if err != nil {
+				return err
+			}
+			if id == keepID {
+				kept = c
+			}
+		}
+		if err := reassignRelatedRecords(ctx, tx, keepID, mergeID); err != nil {
+			return err
+		}
+		_, err := tx.SoftDeleteCustomer(ctx, mergeID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("merge customers: %w", err)
+	}
+	s.publish(ctx, EventUpdated, keepID)
+	s.publish(ctx, EventDeleted, mergeID)
+	return kept, nil
+}
+
+// reassignRelatedRecords moves every record owned by mergeID over to keepID.
+// Credentials are not moved but revoked: mergeID's API token must not start
+// acting for keepID, and its pending email verification is for an address
+// keepID does not have. As tables referencing customers are added, handle
+// their rows here through tx so they commit or roll back with the merge.
+func reassignRelatedRecords(ctx context.Context, tx Store, keepID, mergeID int32) error {
+	if err := tx.MoveTags(ctx, mergeID, keepID); err != nil {
+		return err
+	}
+	if err := tx.DeleteAPIToken(ctx, mergeID); err != nil {
+		return err
+	}
+	return tx.DeleteEmailVerification(ctx, mergeID)
+}
@@ -0,0 +1,46 @@
+package customer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	ErrNameInvalidUTF8  = errors.New("name must be valid UTF-8")
+	ErrNameTooLong      = errors.New("name is too long")
+	ErrNameTooManyBytes = errors.New("name is too large to store")
+)
+
+// NameLimits bounds customer names. MaxLength counts characters, the length
+// a person sees; MaxBytes counts the UTF-8 bytes the column stores, which
+// names in non-Latin scripts reach well before MaxLength. Zero disables
+// either limit.
+type NameLimits struct {
+	MaxLength int
+	MaxBytes  int
+}
+
+// normalizeName trims surrounding whitespace and composes the name to NFC,
+// so "e" plus a combining accent is stored, counted and compared as the
+// single "é" it looks like. It rejects a name left empty, invalid UTF-8 and
+// names over either limit, each with its own error.
+func (s *Service) normalizeName(name string) (string, error) {
+	if !utf8.ValidString(name) {
+		return "", ErrNameInvalidUTF8
+	}
+	name = norm.NFC.String(strings.TrimSpace(name))
+	if name == "" {
+		return "", ErrNameRequired
+	}
+	if limit := s.names.MaxLength; limit > 0 && utf8.RuneCountInString(name) > limit {
+		return "", fmt.Errorf("%w: at most %d characters are allowed", ErrNameTooLong, limit)
+	}
+	if limit := s.names.MaxBytes; limit > 0 && len(name) > limit {
+		return "", fmt.Errorf("%w: at most %d bytes of UTF-8 are allowed", ErrNameTooManyBytes, limit)
+	}
+	return name, nil
+}
@@ -0,0 +1,32 @@
+package customer
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrPasswordContainsIdentity rejects a password built from the customer's own
+// name or email, the first thing an attacker who knows them would try
+var ErrPasswordContainsIdentity = errors.New("password must not contain your name or the part of your email before the @")
+
+// minIdentityLength is the shortest name or email local part a password may
+// not contain; shorter ones only reject the password when equal to it, since
+// a two-letter name turns up inside many unrelated passwords
+const minIdentityLength = 3
+
+// checkPasswordIdentity returns ErrPasswordContainsIdentity when password,
+// ignoring case, equals or contains name or the local part of email
+func checkPasswordIdentity(password, name, email string) error {
+	password = strings.ToLower(password)
+	localPart, _, _ := strings.Cut(email, "@")
+	for _, identity := range []string{name, localPart} {
+		identity = strings.ToLower(strings.TrimSpace(identity))
+		if identity == "" {
+			continue
+		}
+		if password == identity || (len(identity) >= minIdentityLength && strings.Contains(password, identity)) {
+			return ErrPasswordContainsIdentity
+		}
+	}
+	return nil
+}
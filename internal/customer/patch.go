@@ -0,0 +1,104 @@
+package customer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PatchInput holds a partial update. A nil field is left unchanged; a pointer
+// to "" clears the field where that is allowed and is rejected otherwise.
+type PatchInput struct {
+	Name      *string
+	Email     *string
+	Password  *string
+	Phone     *string
+	AvatarURL *string
+
+	// IfUpdatedAt, when set, applies the patch only if the customer has not
+	// been modified since this updated_at value
+	IfUpdatedAt *time.Time
+}
+
+// PatchCustomer validates and applies the fields present in the input,
+// reporting whether any stored value actually changed
+func (s *Service) PatchCustomer(ctx context.Context, id int32, in PatchInput) (*database.Customer, bool, error) {
+	var name, email, hash, phone, avatarURL *string
+
+	if in.Name != nil {
+		normalized, err := s.normalizeName(*in.Name)
+		if err != nil {
+			return nil, false, err
+		}
+		name = &normalized
+	}
+	if in.Email != nil {
+		normalized, err := s.validateEmail(*in.Email)
+		if err != nil {
+			return nil, false, err
+		}
+		email = &normalized
+	}
+	if in.Phone != nil {
+		normalized, err := ValidatePhone(*in.Phone)
+		if err != nil {
+			return nil, false, err
+		}
+		phone = &normalized
+	}
+	if in.AvatarURL != nil {
+		normalized, err := ValidateAvatarURL(*in.AvatarURL)
+		if err != nil {
+			return nil, false, err
+		}
+		avatarURL = &normalized
+	}
+	if in.Password != nil {
+		if err := checkPasswordLength(*in.Password); err != nil {
+			return nil, false, err
+		}
+		if err := s.checkPatchedPasswordIdentity(ctx, id, *in.Password, name, email); err != nil {
+			return nil, false, err
+		}
+		if err := s.checkBreachedPassword(ctx, *in.Password); err != nil {
+			return nil, false, err
+		}
+		hashed, err := bcrypt.GenerateFromPassword([]byte(*in.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, false, fmt.Errorf("hash password: %w", err)
+		}
+		h := string(hashed)
+		hash = &h
+	}
+
+	c, changed, err := s.repository.PatchCustomer(ctx, id, name, email, hash, phone, avatarURL, in.IfUpdatedAt)
+	if err != nil {
+		return nil, false, fmt.Errorf("patch customer: %w", err)
+	}
+	if changed {
+		s.publish(ctx, EventUpdated, c.ID)
+	}
+	return c, changed, nil
+}
+
+// checkPatchedPasswordIdentity checks a new password against the name and
+// email the customer will have after the patch, reading whichever of them the
+// patch leaves unchanged from the stored customer
+func (s *Service) checkPatchedPasswordIdentity(ctx context.Context, id int32, password string, name, email *string) error {
+	if name == nil || email == nil {
+		current, err := s.repository.FindCustomerByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("patch customer: %w", err)
+		}
+		if name == nil {
+			name = &current.Name
+		}
+		if email == nil {
+			email = &current.Email
+		}
+	}
+	return checkPasswordIdentity(password, *name, *email)
+}
@@ -0,0 +1,128 @@
+package customer_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/clock"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+)
+
+// patchFields describes each field PatchInput can carry: how to set it, how
+// to read it back, and what an explicit "" does to it
+var patchFields = []struct {
+	name string
+	set  func(in *customer.PatchInput, v string)
+	get  func(c *database.Customer) string
+	// newValue is a valid value different from the stored one
+	newValue string
+	// emptyErr is what an explicit "" returns; nil means "" clears the field
+	emptyErr error
+}{
+	{"name", func(in *customer.PatchInput, v string) { in.Name = &v }, func(c *database.Customer) string { return c.Name }, "Jane Roe", customer.ErrNameRequired},
+	{"email", func(in *customer.PatchInput, v string) { in.Email = &v }, func(c *database.Customer) string { return c.Email }, "jane.roe@example.com", customer.ErrInvalidEmail},
+	{"password", func(in *customer.PatchInput, v string) { in.Password = &v }, func(c *database.Customer) string { return c.Password }, "another-long-secret", customer.ErrWeakPassword},
+	{"phone", func(in *customer.PatchInput, v string) { in.Phone = &v }, func(c *database.Customer) string { return c.Phone.String }, "+14155550199", nil},
+	{"avatar_url", func(in *customer.PatchInput, v string) { in.AvatarURL = &v }, func(c *database.Customer) string { return c.AvatarUrl.String }, "https://example.com/new.png", nil},
+}
+
+func newPatchService(t *testing.T) (*customer.Service, *database.Customer) {
+	t.Helper()
+	service := customer.NewService(customer.NewMemoryRepository(false), nil, nil, clock.Real{}, nil, 0, customer.NameLimits{})
+	c, err := service.RegisterCustomer(context.Background(), customer.RegisterInput{
+		Name:      "Jane Doe",
+		Email:     "jane@example.com",
+		Password:  "correct-horse-battery",
+		Phone:     "+14155550100",
+		AvatarURL: "https://example.com/jane.png",
+	})
+	if err != nil {
+		t.Fatalf("RegisterCustomer: %v", err)
+	}
+	return service, c
+}
+
+func TestPatchCustomerOmittedFieldsAreUnchanged(t *testing.T) {
+	service, original := newPatchService(t)
+	c, changed, err := service.PatchCustomer(context.Background(), original.ID, customer.PatchInput{})
+	if err != nil {
+		t.Fatalf("PatchCustomer: %v", err)
+	}
+	if changed {
+		t.Error("an empty patch reported a change")
+	}
+	if *c != *original {
+		t.Errorf("an empty patch changed the customer:\n got %+v\nwant %+v", c, original)
+	}
+}
+
+func TestPatchCustomerFields(t *testing.T) {
+	ctx := context.Background()
+	for _, field := range patchFields {
+		t.Run(field.name+" set", func(t *testing.T) {
+			service, original := newPatchService(t)
+			var in customer.PatchInput
+			field.set(&in, field.newValue)
+
+			c, changed, err := service.PatchCustomer(ctx, original.ID, in)
+			if err != nil || !changed {
+				t.Fatalf("PatchCustomer = changed %v, %v", changed, err)
+			}
+			if field.name == "password" {
+				if c.Password == original.Password || c.Password == field.newValue {
+					t.Errorf("password stored as %q, want a new hash", c.Password)
+				}
+			} else if got := field.get(c); got != field.newValue {
+				t.Errorf("%s = %q, want %q", field.name, got, field.newValue)
+			}
+			// Every other field is omitted and must keep its value
+			for _, other := range patchFields {
+				if other.name != field.name && other.get(c) != other.get(original) {
+					t.Errorf("patching %s changed %s from %q to %q", field.name, other.name, other.get(original), other.get(c))
+				}
+			}
+		})
+
+		t.Run(field.name+" explicit empty", func(t *testing.T) {
+			service, original := newPatchService(t)
+			var in customer.PatchInput
+			field.set(&in, "")
+
+			c, changed, err := service.PatchCustomer(ctx, original.ID, in)
+			if field.emptyErr != nil {
+				if !errors.Is(err, field.emptyErr) {
+					t.Fatalf("err = %v, want %v", err, field.emptyErr)
+				}
+				stored, err := service.GetCustomerByID(ctx, original.ID)
+				if err != nil {
+					t.Fatalf("GetCustomerByID: %v", err)
+				}
+				if field.get(stored) != field.get(original) {
+					t.Errorf("rejected patch changed %s to %q", field.name, field.get(stored))
+				}
+				return
+			}
+			if err != nil || !changed {
+				t.Fatalf("PatchCustomer = changed %v, %v", changed, err)
+			}
+			if got := field.get(c); got != "" {
+				t.Errorf("%s = %q, want it cleared", field.name, got)
+			}
+			if field.name == "phone" && c.Phone.Valid || field.name == "avatar_url" && c.AvatarUrl.Valid {
+				t.Errorf("%s cleared to \"\" rather than null", field.name)
+			}
+		})
+	}
+}
+
+func TestPatchCustomerPasswordTooLong(t *testing.T) {
+	service, original := newPatchService(t)
+	password := strings.Repeat("x", 80)
+	_, _, err := service.PatchCustomer(context.Background(), original.ID, customer.PatchInput{Password: &password})
+	if !errors.Is(err, customer.ErrPasswordTooLong) {
+		t.Fatalf("err = %v, want ErrPasswordTooLong", err)
+	}
+}
@@ -0,0 +1,28 @@
+package customer
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidPhone = errors.New("phone must be in E.164 format, e.g. +14155552671")
+
+// e164 is a plus sign followed by up to 15 digits, the first of which is non-zero
+var e164 = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+// phoneSeparators are stripped before validation so "+1 (415) 555-2671" is accepted
+var phoneSeparators = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+
+// ValidatePhone normalizes and validates an optional phone number. An empty
+// string means no phone and is returned unchanged.
+func ValidatePhone(phone string) (string, error) {
+	phone = phoneSeparators.Replace(strings.TrimSpace(phone))
+	if phone == "" {
+		return "", nil
+	}
+	if !e164.MatchString(phone) {
+		return "", ErrInvalidPhone
+	}
+	return phone, nil
+}
@@ -0,0 +1,187 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	minPasswordLength = 8
+	// maxPasswordBytes is the most bcrypt hashes; it refuses longer input
+	maxPasswordBytes = 72
+)
+
+var (
+	ErrNameRequired    = errors.New("name is required")
+	ErrInvalidEmail    = errors.New("invalid email address")
+	ErrWeakPassword    = errors.New("password must be at least 8 characters")
+	ErrPasswordTooLong = errors.New("password must be at most 72 bytes")
+)
+
+// RegisterInput is everything needed to sign up a new customer
+type RegisterInput struct {
+	Name     string
+	Email    string
+	Password string
+	// Phone and AvatarURL are optional
+	Phone     string
+	AvatarURL string
+}
+
+// DuplicateEmailPolicy decides what registering an email that a customer
+// already holds does
+type DuplicateEmailPolicy string
+
+const (
+	// DuplicateEmailReject fails with ErrEmailAlreadyExists
+	DuplicateEmailReject DuplicateEmailPolicy = "reject"
+	// DuplicateEmailUpdate overwrites the existing customer with the input
+	DuplicateEmailUpdate DuplicateEmailPolicy = "update"
+	// DuplicateEmailIgnore returns the existing customer unchanged
+	DuplicateEmailIgnore DuplicateEmailPolicy = "ignore"
+)
+
+// RegisterCustomer validates the input, hashes the password and stores the
+// customer. Rejected input is reported as a *ValidationError listing every
+// bad field, each wrapping ErrNameRequired, ErrNameInvalidUTF8, ErrNameTooLong,
+// ErrNameTooManyBytes, ErrInvalidEmail, ErrDisposableEmail,
+// ErrInvalidPhone, ErrInvalidAvatarURL, ErrWeakPassword, ErrPasswordTooLong,
+// ErrPasswordContainsIdentity or ErrBreachedPassword; a taken email returns ErrEmailAlreadyExists.
+func (s *Service) RegisterCustomer(ctx context.Context, in RegisterInput) (*database.Customer, error) {
+	c, _, err := s.RegisterCustomerOnDuplicate(ctx, in, DuplicateEmailReject)
+	return c, err
+}
+
+// RegisterCustomerOnDuplicate is RegisterCustomer with policy deciding what a
+// taken email does; created is false when an existing customer is returned.
+// A soft-deleted customer still holding the email gives ErrEmailAlreadyExists
+// under every policy.
+func (s *Service) RegisterCustomerOnDuplicate(ctx context.Context, in RegisterInput, policy DuplicateEmailPolicy) (c *database.Customer, created bool, err error) {
+	reg, err := s.prepareRegistration(ctx, in)
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch policy {
+	case DuplicateEmailUpdate:
+		c, created, err = s.repository.UpsertCustomerByEmail(ctx, reg.name, reg.email, reg.passwordHash, reg.phone, reg.avatarURL)
+		if err != nil {
+			return nil, false, fmt.Errorf("register customer: %w", err)
+		}
+		if !created {
+			s.publish(ctx, EventUpdated, c.ID)
+			return c, false, nil
+		}
+	case DuplicateEmailIgnore:
+		// The insert runs under its own savepoint: a failed statement aborts
+		// the transaction it runs in, and the lookup below must still work
+		// inside a request transaction
+		err = s.repository.RunInTx(ctx, func(tx Store) error {
+			c, err = tx.CreateNewCustomer(ctx, reg.name, reg.email, reg.passwordHash, reg.phone, reg.avatarURL)
+			return err
+		})
+		if errors.Is(err, ErrEmailAlreadyExists) {
+			existing, findErr := s.repository.FindCustomerByEmail(ctx, reg.email)
+			if findErr == nil {
+				return existing, false, nil
+			}
+			// Not found means a soft-deleted customer holds the email, so
+			// the conflict stands
+			if !errors.Is(findErr, ErrCustomerNotFound) {
+				return nil, false, fmt.Errorf("register customer: %w", findErr)
+			}
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("register customer: %w", err)
+		}
+	default:
+		c, err = s.repository.CreateNewCustomer(ctx, reg.name, reg.email, reg.passwordHash, reg.phone, reg.avatarURL)
+		if err != nil {
+			return nil, false, fmt.Errorf("register customer: %w", err)
+		}
+	}
+	s.publish(ctx, EventCreated, c.ID)
+	return c, true, nil
+}
+
+// registration is register input that passed validation, ready to store
+type registration struct {
+	name, email, passwordHash, phone, avatarURL string
+}
+
+// prepareRegistration validates in and hashes its password. Rejected input
+// is reported as a *ValidationError listing every bad field.
+func (s *Service) prepareRegistration(ctx context.Context, in RegisterInput) (registration, error) {
+	var invalid ValidationError
+	name, err := s.normalizeName(in.Name)
+	invalid.check("name", err)
+	email, err := s.validateEmail(in.Email)
+	invalid.check("email", err)
+	phone, err := ValidatePhone(in.Phone)
+	invalid.check("phone", err)
+	avatarURL, err := ValidateAvatarURL(in.AvatarURL)
+	invalid.check("avatar_url", err)
+	if err := checkPasswordLength(in.Password); err != nil {
+		invalid.check("password", err)
+	} else {
+		invalid.check("password", checkPasswordIdentity(in.Password, in.Name, in.Email))
+	}
+	if err := invalid.errOrNil(); err != nil {
+		return registration{}, err
+	}
+	// The breach check calls an external service, so it only runs for
+	// otherwise valid input
+	invalid.check("password", s.checkBreachedPassword(ctx, in.Password))
+	if err := invalid.errOrNil(); err != nil {
+		return registration{}, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(in.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return registration{}, fmt.Errorf("hash password: %w", err)
+	}
+	return registration{name: name, email: email, passwordHash: string(hash), phone: phone, avatarURL: avatarURL}, nil
+}
+
+// checkPasswordLength returns ErrWeakPassword for a password shorter than
+// minPasswordLength and ErrPasswordTooLong for one bcrypt cannot hash
+func checkPasswordLength(password string) error {
+	switch {
+	case len(password) < minPasswordLength:
+		return ErrWeakPassword
+	case len(password) > maxPasswordBytes:
+		return ErrPasswordTooLong
+	}
+	return nil
+}
+
+// normalizeEmail accepts a bare address such as "jane@example.com", rejecting
+// display-name forms like "Jane <jane@example.com>", and lowercases it, as
+// emails are stored and compared in lower case
+func normalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", ErrInvalidEmail
+	}
+	return strings.ToLower(email), nil
+}
+
+// validateEmail normalizes email and rejects disposable domains when a
+// blocklist is configured
+func (s *Service) validateEmail(email string) (string, error) {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return "", err
+	}
+	if s.disposable != nil && s.disposable.Contains(email) {
+		return "", ErrDisposableEmail
+	}
+	return email, nil
+}
@@ -0,0 +1,154 @@
+package customer_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/clock"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+)
+
+// breachedPasswords is a BreachChecker that knows a fixed list of passwords
+type breachedPasswords []string
+
+func (b breachedPasswords) IsBreached(ctx context.Context, password string) (bool, error) {
+	for _, breached := range b {
+		if password == breached {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func validRegisterInput() customer.RegisterInput {
+	return customer.RegisterInput{
+		Name:     "Jane Doe",
+		Email:    "jane@example.com",
+		Password: "correct-horse-battery",
+	}
+}
+
+func newRegisterService(t *testing.T) (*customer.Service, customer.Store) {
+	t.Helper()
+	disposable, err := customer.LoadDisposableDomains("")
+	if err != nil {
+		t.Fatalf("LoadDisposableDomains: %v", err)
+	}
+	repo := customer.NewMemoryRepository(false)
+	breached := breachedPasswords{"password123"}
+	return customer.NewService(repo, breached, disposable, clock.Real{}, nil, 0, customer.NameLimits{}), repo
+}
+
+func TestRegisterCustomer(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newRegisterService(t)
+
+	in := validRegisterInput()
+	in.Email = "  Jane@Example.com "
+	c, err := service.RegisterCustomer(ctx, in)
+	if err != nil {
+		t.Fatalf("RegisterCustomer: %v", err)
+	}
+	if c.Email != "jane@example.com" {
+		t.Errorf("email = %q, want it trimmed and lowercased", c.Email)
+	}
+	if c.Password == in.Password || !strings.HasPrefix(c.Password, "$2") {
+		t.Errorf("password stored as %q, want a bcrypt hash", c.Password)
+	}
+	if _, err := repo.FindCustomerByID(ctx, c.ID); err != nil {
+		t.Errorf("registered customer not stored: %v", err)
+	}
+}
+
+func TestRegisterCustomerRejectsInput(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		change  func(in *customer.RegisterInput)
+		field   string
+		wantErr error
+	}{
+		{"blank name", func(in *customer.RegisterInput) { in.Name = "   " }, "name", customer.ErrNameRequired},
+		{"invalid email", func(in *customer.RegisterInput) { in.Email = "not-an-email" }, "email", customer.ErrInvalidEmail},
+		{"display-name email", func(in *customer.RegisterInput) { in.Email = "Jane <jane@example.com>" }, "email", customer.ErrInvalidEmail},
+		{"disposable email", func(in *customer.RegisterInput) { in.Email = "jane@mailinator.com" }, "email", customer.ErrDisposableEmail},
+		{"invalid phone", func(in *customer.RegisterInput) { in.Phone = "call me" }, "phone", customer.ErrInvalidPhone},
+		{"invalid avatar url", func(in *customer.RegisterInput) { in.AvatarURL = "ftp://example.com/a.png" }, "avatar_url", customer.ErrInvalidAvatarURL},
+		{"short password", func(in *customer.RegisterInput) { in.Password = "short" }, "password", customer.ErrWeakPassword},
+		{"password over 72 bytes", func(in *customer.RegisterInput) { in.Password = strings.Repeat("x", 80) }, "password", customer.ErrPasswordTooLong},
+		{"password over 72 bytes in multibyte runes", func(in *customer.RegisterInput) { in.Password = strings.Repeat("é", 37) }, "password", customer.ErrPasswordTooLong},
+		{"password containing the name", func(in *customer.RegisterInput) { in.Password = "janedoe-2024!"; in.Name = "janedoe" }, "password", customer.ErrPasswordContainsIdentity},
+		{"breached password", func(in *customer.RegisterInput) { in.Password = "password123" }, "password", customer.ErrBreachedPassword},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo := newRegisterService(t)
+			in := validRegisterInput()
+			tt.change(&in)
+
+			_, err := service.RegisterCustomer(ctx, in)
+			var invalid *customer.ValidationError
+			if !errors.As(err, &invalid) {
+				t.Fatalf("err = %v, want a *ValidationError", err)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if len(invalid.Fields) != 1 || invalid.Fields[0].Field != tt.field {
+				t.Errorf("rejected fields = %v, want only %s", invalid.Fields, tt.field)
+			}
+			if n, err := repo.CountCustomers(ctx, customer.ListFilter{}); err != nil || n != 0 {
+				t.Errorf("%d customers stored after a rejected registration, err %v", n, err)
+			}
+		})
+	}
+}
+
+func TestRegisterCustomerPasswordOfMaxLength(t *testing.T) {
+	service, _ := newRegisterService(t)
+	in := validRegisterInput()
+	in.Password = strings.Repeat("x", 72)
+	if _, err := service.RegisterCustomer(context.Background(), in); err != nil {
+		t.Fatalf("72-byte password: %v", err)
+	}
+}
+
+func TestRegisterCustomerReportsEveryField(t *testing.T) {
+	service, _ := newRegisterService(t)
+	_, err := service.RegisterCustomer(context.Background(), customer.RegisterInput{Email: "nope", Password: "short"})
+
+	var invalid *customer.ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("err = %v, want a *ValidationError", err)
+	}
+	var fields []string
+	for _, f := range invalid.Fields {
+		fields = append(fields, f.Field)
+	}
+	if strings.Join(fields, ",") != "name,email,password" {
+		t.Errorf("rejected fields = %v, want name, email and password", fields)
+	}
+}
+
+func TestRegisterCustomerEmailTaken(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newRegisterService(t)
+	if _, err := service.RegisterCustomer(ctx, validRegisterInput()); err != nil {
+		t.Fatalf("RegisterCustomer: %v", err)
+	}
+
+	in := validRegisterInput()
+	in.Name = "Another Jane"
+	in.Email = "JANE@example.com"
+	_, err := service.RegisterCustomer(ctx, in)
+	if !errors.Is(err, customer.ErrEmailAlreadyExists) {
+		t.Fatalf("err = %v, want ErrEmailAlreadyExists", err)
+	}
+	var invalid *customer.ValidationError
+	if errors.As(err, &invalid) {
+		t.Errorf("a taken email is reported as a validation error: %v", err)
+	}
+}
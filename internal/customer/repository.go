@@ -5,30 +5,229 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/ctxkeys"
 	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
-var ErrCustomerNotFound = errors.New("customer not found")
+var (
+	ErrCustomerNotFound   = errors.New("customer not found")
+	ErrEmailAlreadyExists = errors.New("email already exists")
+	ErrNameAlreadyExists  = errors.New("name already exists")
+	// ErrAlreadyExists is a unique violation on a constraint that
+	// uniqueConstraintErrors does not know about
+	ErrAlreadyExists = errors.New("customer conflicts with an existing customer")
+	// ErrStaleCustomer means the customer was modified since the version the
+	// caller based its update on
+	ErrStaleCustomer = errors.New("customer was modified by another request")
+)
+
+// uniqueViolation is the Postgres error code for a unique constraint violation
+const uniqueViolation = "23505"
+
+// nameUniqueIndex is the optional index backing Config.RequireUniqueName
+const nameUniqueIndex = "customers_name_key"
+
+// emailLowerUniqueIndex keeps emails unique regardless of case, from
+// migration 0010
+const emailLowerUniqueIndex = "customers_email_lower_key"
+
+// uniqueConstraintErrors maps each unique constraint on customers to the
+// sentinel naming the field it protects. Add new unique constraints here.
+var uniqueConstraintErrors = map[string]error{
+	"customers_email_key": ErrEmailAlreadyExists,
+	emailLowerUniqueIndex: ErrEmailAlreadyExists,
+	nameUniqueIndex:       ErrNameAlreadyExists,
+}
+
+// TxBeginner starts database transactions; *pgxpool.Pool satisfies it, and
+// so does pgx.Tx, whose Begin opens a savepoint
+type TxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
 
 // Repository is the concrete repository for customer-related database operations
 type Repository struct {
-	queries *database.Queries
+	db      TxBeginner
+	queries database.Querier
+	// bound is set on repositories made by WithTx, which keep to their own
+	// transaction even when the context carries another
+	bound bool
 }
 
 // NewCustomerRepository is the constructor for CustomerRepository
-func NewCustomerRepository(q *database.Queries) *Repository {
-	return &Repository{queries: q}
+func NewCustomerRepository(db TxBeginner, q *database.Queries) *Repository {
+	return &Repository{db: db, queries: q}
+}
+
+// WithTx returns a copy of the repository whose queries run inside tx. Its
+// RunInTx nests inside tx as a savepoint instead of opening a new transaction.
+func (r *Repository) WithTx(tx pgx.Tx) *Repository {
+	if mtx, ok := tx.(*memoryTx); ok {
+		return &Repository{db: tx, queries: &memoryQueries{store: mtx.store, inTx: true}, bound: true}
+	}
+	return &Repository{db: tx, queries: database.New(tx), bound: true}
+}
+
+// forCtx returns the repository a call made with ctx runs on: one bound to
+// the request transaction stored by middleware.Transaction, when ctx carries
+// one, and r otherwise
+func (r *Repository) forCtx(ctx context.Context) *Repository {
+	if tx := ctxkeys.Tx(ctx); tx != nil && !r.bound {
+		return r.WithTx(tx)
+	}
+	return r
+}
+
+// Begin starts a transaction on the repository's database, so the
+// repository can serve as the TxBeginner of middleware.Transaction
+func (r *Repository) Begin(ctx context.Context) (pgx.Tx, error) {
+	return r.db.Begin(ctx)
+}
+
+// RunInTx runs fn with a repository bound to a single transaction,
+// committing when fn succeeds and rolling back otherwise
+func (r *Repository) RunInTx(ctx context.Context, fn func(tx Store) error) error {
+	tx, err := r.forCtx(ctx).db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(r.WithTx(tx)); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// FindAllCustomers returns the page of customers selected by filter
+func (r *Repository) FindAllCustomers(ctx context.Context, filter ListFilter) ([]database.Customer, error) {
+	customers, err := r.forCtx(ctx).queries.ListCustomers(ctx, filter.listParams())
+	if err != nil {
+		return nil, fmt.Errorf("list customers: %w", err)
+	}
+	return customers, nil
 }
 
-// FindAllCustomers returns all customers
-func (r *Repository) FindAllCustomers(ctx context.Context) ([]database.Customer, error) {
-	return r.queries.ListCustomers(ctx)
+// CountCustomers returns how many customers FindAllCustomers can page through
+// for filter, ignoring its limit and offset
+func (r *Repository) CountCustomers(ctx context.Context, filter ListFilter) (int64, error) {
+	count, err := r.forCtx(ctx).queries.CountCustomers(ctx, filter.countParams())
+	if err != nil {
+		return 0, fmt.Errorf("count customers: %w", err)
+	}
+	return count, nil
+}
+
+// SearchCustomers returns the page of customers whose name or email contains
+// query, case-insensitively, with an exact email match first
+func (r *Repository) SearchCustomers(ctx context.Context, query string, limit, offset int32) ([]database.Customer, error) {
+	customers, err := r.forCtx(ctx).queries.SearchCustomers(ctx, database.SearchCustomersParams{
+		Pattern: containsPattern(query),
+		Query:   query,
+		Limit:   limit,
+		Offset:  offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search customers: %w", err)
+	}
+	return customers, nil
+}
+
+// CountSearchCustomers returns how many customers SearchCustomers can page
+// through for query
+func (r *Repository) CountSearchCustomers(ctx context.Context, query string) (int64, error) {
+	count, err := r.forCtx(ctx).queries.CountSearchCustomers(ctx, containsPattern(query))
+	if err != nil {
+		return 0, fmt.Errorf("count search customers: %w", err)
+	}
+	return count, nil
+}
+
+// likeEscaper escapes the LIKE wildcards so user input matches literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// containsPattern builds an ILIKE pattern matching any value containing s
+func containsPattern(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
+
+func activeFilter(active *bool) pgtype.Bool {
+	if active == nil {
+		return pgtype.Bool{}
+	}
+	return pgtype.Bool{Bool: *active, Valid: true}
+}
+
+func tagFilter(tag string) pgtype.Text {
+	if tag == "" {
+		return pgtype.Text{}
+	}
+	normalized, _ := normalizeTag(tag)
+	return pgtype.Text{String: normalized, Valid: true}
+}
+
+// GetCustomerStats returns aggregate customer counts from a single query
+func (r *Repository) GetCustomerStats(ctx context.Context) (*database.GetCustomerStatsRow, error) {
+	stats, err := r.forCtx(ctx).queries.GetCustomerStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get customer stats: %w", err)
+	}
+	return &stats, nil
+}
+
+// CountSignupsByDay counts the customers created in [since, until) per day.
+// Days without signups are left out.
+func (r *Repository) CountSignupsByDay(ctx context.Context, since, until time.Time) ([]database.CountSignupsByDayRow, error) {
+	params := database.CountSignupsByDayParams{
+		Since: pgtype.Timestamp{Time: since, Valid: true},
+		Until: pgtype.Timestamp{Time: until, Valid: true},
+	}
+	rows, err := r.forCtx(ctx).queries.CountSignupsByDay(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("count signups by day: %w", err)
+	}
+	return rows, nil
+}
+
+// IterateCustomers walks every customer in ID order, handing fn one batch at a
+// time so callers never hold the whole table in memory. It stops at the first
+// error returned by fn.
+func (r *Repository) IterateCustomers(ctx context.Context, batchSize int, fn func([]database.Customer) error) error {
+	var afterID int32
+	for {
+		params := database.ListCustomersAfterIDParams{
+			ID:    afterID,
+			Limit: int32(batchSize),
+		}
+		batch, err := r.forCtx(ctx).queries.ListCustomersAfterID(ctx, params)
+		if err != nil {
+			return fmt.Errorf("list customers after id: %w", err)
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+		afterID = batch[len(batch)-1].ID
+	}
 }
 
 // FindCustomerByID returns a customer by ID
 func (r *Repository) FindCustomerByID(ctx context.Context, id int32) (*database.Customer, error) {
-	customer, err := r.queries.GetCustomerByID(ctx, id)
+	customer, err := r.forCtx(ctx).queries.GetCustomerByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrCustomerNotFound
@@ -38,9 +237,34 @@ func (r *Repository) FindCustomerByID(ctx context.Context, id int32) (*database.
 	return &customer, nil
 }
 
-// FindCustomerByEmail returns a customer by email
+// ExistsByID reports whether a customer with id exists, without loading it
+func (r *Repository) ExistsByID(ctx context.Context, id int32) (bool, error) {
+	exists, err := r.forCtx(ctx).queries.ExistsCustomerByID(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("exists customer by id: %w", err)
+	}
+	return exists, nil
+}
+
+// GetCustomerByIDForUpdate returns a customer by ID and locks its row until
+// the transaction ends, so concurrent read-modify-write cycles on the same
+// customer run one after another. It is only valid inside RunInTx: call it on
+// the Store passed to the callback. Outside a transaction the lock is
+// released as soon as the query returns and protects nothing.
+func (r *Repository) GetCustomerByIDForUpdate(ctx context.Context, id int32) (*database.Customer, error) {
+	customer, err := r.forCtx(ctx).queries.GetCustomerByIDForUpdate(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCustomerNotFound
+		}
+		return nil, fmt.Errorf("get customer by id for update: %w", err)
+	}
+	return &customer, nil
+}
+
+// FindCustomerByEmail returns a customer by email, matched case-insensitively
 func (r *Repository) FindCustomerByEmail(ctx context.Context, email string) (*database.Customer, error) {
-	customer, err := r.queries.GetCustomerByEmail(ctx, email)
+	customer, err := r.forCtx(ctx).queries.GetCustomerByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrCustomerNotFound
@@ -50,20 +274,95 @@ func (r *Repository) FindCustomerByEmail(ctx context.Context, email string) (*da
 	return &customer, nil
 }
 
-// CreateNewCustomer creates a new customer
-func (r *Repository) CreateNewCustomer(ctx context.Context, name, email, password string) (*database.Customer, error) {
+// FindCustomersByEmails looks up many customers in one query, matching emails
+// case-insensitively. The result is keyed by lowercased email; emails with no
+// customer are absent.
+func (r *Repository) FindCustomersByEmails(ctx context.Context, emails []string) (map[string]database.Customer, error) {
+	lowered := make([]string, len(emails))
+	for i, email := range emails {
+		lowered[i] = strings.ToLower(email)
+	}
+	customers, err := r.forCtx(ctx).queries.ListCustomersByEmails(ctx, lowered)
+	if err != nil {
+		return nil, fmt.Errorf("find customers by emails: %w", err)
+	}
+	found := make(map[string]database.Customer, len(customers))
+	for _, c := range customers {
+		found[strings.ToLower(c.Email)] = c
+	}
+	return found, nil
+}
+
+// CreateNewCustomer creates a new customer; an empty phone or avatarURL is stored as NULL
+func (r *Repository) CreateNewCustomer(ctx context.Context, name, email, password, phone, avatarURL string) (*database.Customer, error) {
 	params := database.CreateCustomerParams{
-		Name:     name,
-		Email:    email,
-		Password: password,
+		Name:      name,
+		Email:     email,
+		Password:  password,
+		Phone:     pgtype.Text{String: phone, Valid: phone != ""},
+		AvatarUrl: pgtype.Text{String: avatarURL, Valid: avatarURL != ""},
 	}
-	customer, err := r.queries.CreateCustomer(ctx, params)
+	customer, err := r.forCtx(ctx).queries.CreateCustomer(ctx, params)
 	if err != nil {
+		if conflict := uniqueConflict(err); conflict != nil {
+			return nil, conflict
+		}
 		return nil, fmt.Errorf("create customer: %w", err)
 	}
 	return &customer, nil
 }
 
+// TouchCustomer bumps the customer's updated_at without changing anything
+// else and returns the new value
+func (r *Repository) TouchCustomer(ctx context.Context, id int32) (time.Time, error) {
+	updatedAt, err := r.forCtx(ctx).queries.TouchCustomer(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, ErrCustomerNotFound
+		}
+		return time.Time{}, fmt.Errorf("touch customer: %w", err)
+	}
+	return updatedAt.Time, nil
+}
+
+// UpsertCustomerByEmail creates the customer, or overwrites the name,
+// password, phone and avatar URL of the live customer holding email, and
+// reports whether it created one. A soft-deleted holder of the email still
+// gives ErrEmailAlreadyExists.
+func (r *Repository) UpsertCustomerByEmail(ctx context.Context, name, email, password, phone, avatarURL string) (c *database.Customer, created bool, err error) {
+	params := database.UpsertCustomerByEmailParams{
+		Name:      name,
+		Email:     email,
+		Password:  password,
+		Phone:     pgtype.Text{String: phone, Valid: phone != ""},
+		AvatarUrl: pgtype.Text{String: avatarURL, Valid: avatarURL != ""},
+	}
+	row, err := r.forCtx(ctx).queries.UpsertCustomerByEmail(ctx, params)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, false, ErrEmailAlreadyExists
+		}
+		if conflict := uniqueConflict(err); conflict != nil {
+			return nil, false, conflict
+		}
+		return nil, false, fmt.Errorf("upsert customer: %w", err)
+	}
+	return &database.Customer{
+		ID:            row.ID,
+		Name:          row.Name,
+		Email:         row.Email,
+		Password:      row.Password,
+		CreatedAt:     row.CreatedAt,
+		UpdatedAt:     row.UpdatedAt,
+		IsActive:      row.IsActive,
+		DeletedAt:     row.DeletedAt,
+		Phone:         row.Phone,
+		AvatarUrl:     row.AvatarUrl,
+		LastLoginAt:   row.LastLoginAt,
+		EmailVerified: row.EmailVerified,
+	}, row.Inserted, nil
+}
+
 // UpdateExistingCustomer updates an existing customer
 func (r *Repository) UpdateExistingCustomer(ctx context.Context, id int32, name, email, password string) (*database.Customer, error) {
 	params := database.UpdateCustomerParams{
@@ -72,24 +371,308 @@ func (r *Repository) UpdateExistingCustomer(ctx context.Context, id int32, name,
 		Email:    email,
 		Password: password,
 	}
-	updatedCustomer, err := r.queries.UpdateCustomer(ctx, params)
+	updatedCustomer, err := r.forCtx(ctx).queries.UpdateCustomer(ctx, params)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrCustomerNotFound
 		}
+		if conflict := uniqueConflict(err); conflict != nil {
+			return nil, conflict
+		}
 		return nil, fmt.Errorf("update customer: %w", err)
 	}
 	return &updatedCustomer, nil
 }
 
-// DeleteCustomerByEmail deletes a customer by email
-func (r *Repository) DeleteCustomerByEmail(ctx context.Context, email string) error {
-	rows, err := r.queries.DeleteCustomerByEmail(ctx, email)
+// UpdateCustomerName changes only the customer's name
+func (r *Repository) UpdateCustomerName(ctx context.Context, id int32, name string) (*database.Customer, error) {
+	params := database.UpdateCustomerNameParams{
+		ID:   id,
+		Name: name,
+	}
+	updatedCustomer, err := r.forCtx(ctx).queries.UpdateCustomerName(ctx, params)
 	if err != nil {
-		return fmt.Errorf("delete customer: %w", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCustomerNotFound
+		}
+		if conflict := uniqueConflict(err); conflict != nil {
+			return nil, conflict
+		}
+		return nil, fmt.Errorf("update customer name: %w", err)
+	}
+	return &updatedCustomer, nil
+}
+
+// PatchCustomer updates only the fields that are non-nil, leaving the rest
+// unchanged; a phone or avatarURL pointing to "" clears it. When the values already match
+// nothing is written and changed is false; the current customer is returned either way.
+// A non-nil expectedUpdatedAt makes the update fail with ErrStaleCustomer unless
+// the stored updated_at still matches it.
+func (r *Repository) PatchCustomer(ctx context.Context, id int32, name, email, password, phone, avatarURL *string, expectedUpdatedAt *time.Time) (c *database.Customer, changed bool, err error) {
+	params := database.PatchCustomerParams{
+		ID:        id,
+		Name:      optionalText(name),
+		Email:     optionalText(email),
+		Password:  optionalText(password),
+		Phone:     optionalText(phone),
+		AvatarUrl: optionalText(avatarURL),
 	}
-	if rows == 0 {
-		return ErrCustomerNotFound
+	if expectedUpdatedAt != nil {
+		params.ExpectedUpdatedAt = pgtype.Timestamp{Time: *expectedUpdatedAt, Valid: true}
+	}
+	patchedCustomer, err := r.forCtx(ctx).queries.PatchCustomer(ctx, params)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// The customer doesn't exist, has a different version, or the
+			// patch is a no-op
+			current, err := r.FindCustomerByID(ctx, id)
+			if err != nil {
+				return nil, false, err
+			}
+			if expectedUpdatedAt != nil && !current.UpdatedAt.Time.Equal(*expectedUpdatedAt) {
+				return nil, false, ErrStaleCustomer
+			}
+			return current, false, nil
+		}
+		if conflict := uniqueConflict(err); conflict != nil {
+			return nil, false, conflict
+		}
+		return nil, false, fmt.Errorf("patch customer: %w", err)
+	}
+	return &patchedCustomer, true, nil
+}
+
+// optionalText maps nil to SQL NULL so COALESCE keeps the current value
+func optionalText(s *string) pgtype.Text {
+	if s == nil {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: *s, Valid: true}
+}
+
+// SetCustomerActive activates or deactivates a customer without deleting it
+func (r *Repository) SetCustomerActive(ctx context.Context, id int32, active bool) (*database.Customer, error) {
+	params := database.SetCustomerActiveParams{
+		ID:       id,
+		IsActive: active,
+	}
+	customer, err := r.forCtx(ctx).queries.SetCustomerActive(ctx, params)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCustomerNotFound
+		}
+		return nil, fmt.Errorf("set customer active: %w", err)
+	}
+	return &customer, nil
+}
+
+// SetCustomersActive sets the active status of every customer in ids with a
+// single statement and returns the IDs of the customers updated
+func (r *Repository) SetCustomersActive(ctx context.Context, ids []int32, active bool) ([]int32, error) {
+	params := database.SetCustomersActiveParams{
+		IsActive: active,
+		Ids:      ids,
+	}
+	updated, err := r.forCtx(ctx).queries.SetCustomersActive(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("set customers active: %w", err)
+	}
+	return updated, nil
+}
+
+// UpdateLastLogin records that a customer just logged in. It changes nothing
+// else, not even updated_at.
+func (r *Repository) UpdateLastLogin(ctx context.Context, id int32) error {
+	if err := r.forCtx(ctx).queries.UpdateLastLogin(ctx, id); err != nil {
+		return fmt.Errorf("update last login: %w", err)
+	}
+	return nil
+}
+
+// SoftDeleteCustomer marks a customer as deleted while keeping its row, and
+// returns the customer as it was deleted
+func (r *Repository) SoftDeleteCustomer(ctx context.Context, id int32) (*database.Customer, error) {
+	deletedCustomer, err := r.forCtx(ctx).queries.SoftDeleteCustomer(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCustomerNotFound
+		}
+		return nil, fmt.Errorf("soft delete customer: %w", err)
+	}
+	return &deletedCustomer, nil
+}
+
+// AnonymizeCustomer overwrites a customer's personal data and marks it
+// deleted, keeping the row. Already deleted customers can be anonymized too.
+func (r *Repository) AnonymizeCustomer(ctx context.Context, id int32, name, email, password string) (*database.Customer, error) {
+	params := database.AnonymizeCustomerParams{
+		ID:       id,
+		Name:     name,
+		Email:    email,
+		Password: password,
+	}
+	anonymized, err := r.forCtx(ctx).queries.AnonymizeCustomer(ctx, params)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCustomerNotFound
+		}
+		return nil, fmt.Errorf("anonymize customer: %w", err)
+	}
+	return &anonymized, nil
+}
+
+// DeleteCustomerByEmail deletes a customer by email and returns the IDs of
+// the rows deleted
+func (r *Repository) DeleteCustomerByEmail(ctx context.Context, email string) ([]int32, error) {
+	deleted, err := r.forCtx(ctx).queries.DeleteCustomerByEmail(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("delete customer: %w", err)
+	}
+	if len(deleted) == 0 {
+		return nil, ErrCustomerNotFound
+	}
+	return deleted, nil
+}
+
+// uniqueConflict maps a unique violation to the sentinel for the field that
+// collided, returning nil for any other error
+func uniqueConflict(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != uniqueViolation {
+		return nil
+	}
+	if conflict, ok := uniqueConstraintErrors[pgErr.ConstraintName]; ok {
+		return conflict
+	}
+	return ErrAlreadyExists
+}
+
+// AddTag attaches tag to a customer; adding a tag it already has is a no-op
+func (r *Repository) AddTag(ctx context.Context, id int32, tag string) error {
+	params := database.AddCustomerTagParams{
+		CustomerID: id,
+		Tag:        tag,
+	}
+	if err := r.forCtx(ctx).queries.AddCustomerTag(ctx, params); err != nil {
+		return fmt.Errorf("add customer tag: %w", err)
+	}
+	return nil
+}
+
+// RemoveTag detaches tag from a customer; removing a missing tag is a no-op
+func (r *Repository) RemoveTag(ctx context.Context, id int32, tag string) error {
+	params := database.RemoveCustomerTagParams{
+		CustomerID: id,
+		Tag:        tag,
+	}
+	if err := r.forCtx(ctx).queries.RemoveCustomerTag(ctx, params); err != nil {
+		return fmt.Errorf("remove customer tag: %w", err)
+	}
+	return nil
+}
+
+// ListTags returns a customer's tags in alphabetical order
+func (r *Repository) ListTags(ctx context.Context, id int32) ([]string, error) {
+	tags, err := r.forCtx(ctx).queries.ListCustomerTags(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("list customer tags: %w", err)
+	}
+	return tags, nil
+}
+
+// MoveTags transfers every tag from one customer to another, skipping tags
+// the target already has
+func (r *Repository) MoveTags(ctx context.Context, fromID, toID int32) error {
+	params := database.MoveCustomerTagsParams{
+		FromID: fromID,
+		ToID:   toID,
+	}
+	if err := r.forCtx(ctx).queries.MoveCustomerTags(ctx, params); err != nil {
+		return fmt.Errorf("move customer tags: %w", err)
+	}
+	return nil
+}
+
+// SetAPIToken stores the hash of a customer's API token, replacing any
+// previous one
+func (r *Repository) SetAPIToken(ctx context.Context, id int32, tokenHash string) error {
+	params := database.SetCustomerAPITokenParams{
+		CustomerID: id,
+		TokenHash:  tokenHash,
+	}
+	if err := r.forCtx(ctx).queries.SetCustomerAPIToken(ctx, params); err != nil {
+		return fmt.Errorf("set customer api token: %w", err)
 	}
 	return nil
 }
+
+// DeleteAPIToken revokes a customer's API token, if it has one
+func (r *Repository) DeleteAPIToken(ctx context.Context, id int32) error {
+	if err := r.forCtx(ctx).queries.DeleteCustomerAPIToken(ctx, id); err != nil {
+		return fmt.Errorf("delete customer api token: %w", err)
+	}
+	return nil
+}
+
+// FindCustomerIDByAPIToken returns the ID of the live customer whose API
+// token hashes to tokenHash and whether they are active, or
+// ErrInvalidAPIToken when there is none
+func (r *Repository) FindCustomerIDByAPIToken(ctx context.Context, tokenHash string) (id int32, active bool, err error) {
+	row, err := r.forCtx(ctx).queries.GetCustomerIDByAPITokenHash(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, ErrInvalidAPIToken
+		}
+		return 0, false, fmt.Errorf("get customer by api token: %w", err)
+	}
+	return row.CustomerID, row.IsActive, nil
+}
+
+// SetEmailVerification stores the hash of a token proving the customer owns
+// email, replacing any pending one
+func (r *Repository) SetEmailVerification(ctx context.Context, id int32, email, tokenHash string, expiresAt time.Time) error {
+	params := database.SetEmailVerificationParams{
+		CustomerID: id,
+		TokenHash:  tokenHash,
+		ExpiresAt:  pgtype.Timestamp{Time: expiresAt.UTC(), Valid: true},
+		Email:      email,
+	}
+	if err := r.forCtx(ctx).queries.SetEmailVerification(ctx, params); err != nil {
+		return fmt.Errorf("set email verification: %w", err)
+	}
+	return nil
+}
+
+// FindEmailVerification returns the pending verification with tokenHash, or
+// ErrInvalidVerificationToken when there is none
+func (r *Repository) FindEmailVerification(ctx context.Context, tokenHash string) (*database.CustomerEmailVerification, error) {
+	verification, err := r.forCtx(ctx).queries.GetEmailVerificationByTokenHash(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidVerificationToken
+		}
+		return nil, fmt.Errorf("get email verification: %w", err)
+	}
+	return &verification, nil
+}
+
+// DeleteEmailVerification discards a customer's pending verification, if any
+func (r *Repository) DeleteEmailVerification(ctx context.Context, id int32) error {
+	if err := r.forCtx(ctx).queries.DeleteEmailVerification(ctx, id); err != nil {
+		return fmt.Errorf("delete email verification: %w", err)
+	}
+	return nil
+}
+
+// MarkEmailVerified records that a customer proved they own email. A
+// customer who no longer holds email gives ErrCustomerNotFound.
+func (r *Repository) MarkEmailVerified(ctx context.Context, id int32, email string) (*database.Customer, error) {
+	customer, err := r.forCtx(ctx).queries.MarkEmailVerified(ctx, database.MarkEmailVerifiedParams{ID: id, Email: email})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCustomerNotFound
+		}
+		return nil, fmt.Errorf("mark email verified: %w", err)
+	}
+	return &customer, nil
+}
@@ -0,0 +1,32 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+)
+
+var ErrEmptySearchQuery = errors.New("search query must not be empty")
+
+// SearchCustomers returns one page of the customers whose name or email
+// contains query, together with the total number of matches. A customer whose
+// email equals query ranks first, so typing a full address finds its owner
+// at the top of the list.
+func (s *Service) SearchCustomers(ctx context.Context, query string, limit, offset int32) ([]database.Customer, int64, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, 0, ErrEmptySearchQuery
+	}
+	customers, err := s.repository.SearchCustomers(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("search customers: %w", err)
+	}
+	total, err := s.repository.CountSearchCustomers(ctx, query)
+	if err != nil {
+		return nil, 0, fmt.Errorf("search customers: %w", err)
+	}
+	return customers, total, nil
+}
@@ -2,59 +2,184 @@ package customer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"strings"
+	"time"
 
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/clock"
 	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
 )
 
+var ErrBreachedPassword = errors.New("password has appeared in a data breach")
+
+// BreachChecker reports whether a password is known to have been breached
+type BreachChecker interface {
+	IsBreached(ctx context.Context, password string) (bool, error)
+}
+
 type Service struct {
-	repository *Repository
+	repository    Store
+	breachChecker BreachChecker
+	disposable    *DisposableDomains
+	clock         clock.Clock
+	events        EventPublisher
+	stats         statsCache
+	names         NameLimits
 }
 
-func NewService(repository *Repository) *Service {
-	return &Service{repository: repository}
+// NewService is the constructor for Service; breachChecker may be nil to skip breached-password checks
+// and disposable may be nil to allow every email domain.
+// Every time the service reads in Go comes from clk; timestamps set by the database are unaffected.
+// events may be nil to publish no lifecycle events.
+// statsTTL is how long GetCustomerStats serves a result from memory; zero disables the cache.
+// names bounds the names the service accepts; the zero value only requires a name.
+func NewService(repository Store, breachChecker BreachChecker, disposable *DisposableDomains, clk clock.Clock, events EventPublisher, statsTTL time.Duration, names NameLimits) *Service {
+	return &Service{repository: repository, breachChecker: breachChecker, disposable: disposable, clock: clk, events: events, stats: statsCache{ttl: statsTTL}, names: names}
 }
 
-func (s *Service) GetCustomers(ctx context.Context) ([]database.Customer, error) {
-	c, err := s.repository.FindAllCustomers(ctx)
+// ListCustomers returns the page of customers selected by filter. An invalid
+// filter is returned unwrapped so callers can show it to the client.
+func (s *Service) ListCustomers(ctx context.Context, filter ListFilter) ([]database.Customer, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	c, err := s.repository.FindAllCustomers(ctx, filter)
 	if err != nil {
-		return nil, fmt.Errorf("customer not found %w", err)
+		return nil, fmt.Errorf("list customers: %w", err)
 	}
 	return c, nil
 }
 
+func (s *Service) CountCustomers(ctx context.Context, filter ListFilter) (int64, error) {
+	n, err := s.repository.CountCustomers(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("count customers: %w", err)
+	}
+	return n, nil
+}
+
+func (s *Service) IterateCustomers(ctx context.Context, batchSize int, fn func([]database.Customer) error) error {
+	if err := s.repository.IterateCustomers(ctx, batchSize, fn); err != nil {
+		return fmt.Errorf("iterate customers: %w", err)
+	}
+	return nil
+}
+
 func (s *Service) GetCustomerByID(ctx context.Context, id int32) (*database.Customer, error) {
 	c, err := s.repository.FindCustomerByID(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("no customer with this id has found %w", err)
+		return nil, fmt.Errorf("get customer by id: %w", err)
 	}
 	return c, nil
 }
 
+func (s *Service) CustomerExists(ctx context.Context, id int32) (bool, error) {
+	exists, err := s.repository.ExistsByID(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("customer exists: %w", err)
+	}
+	return exists, nil
+}
+
+// GetCustomerByEmail finds the customer holding email in any case
 func (s *Service) GetCustomerByEmail(ctx context.Context, email string) (*database.Customer, error) {
-	c, err := s.repository.FindCustomerByEmail(ctx, email)
+	c, err := s.repository.FindCustomerByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
 	if err != nil {
-		return nil, fmt.Errorf("no customer with this email has found %w", err)
+		return nil, fmt.Errorf("get customer by email: %w", err)
 	}
 	return c, nil
 }
 
+// CreateCustomer is the positional form of RegisterCustomer.
+//
+// Deprecated: name, email and password are all strings and easy to swap, and
+// optional fields such as the phone cannot be passed. Use RegisterCustomer
+// with a RegisterInput.
 func (s *Service) CreateCustomer(ctx context.Context, name, email, password string) (*database.Customer, error) {
-	c, err := s.repository.CreateNewCustomer(ctx, name, email, password)
+	return s.RegisterCustomer(ctx, RegisterInput{Name: name, Email: email, Password: password})
+}
+
+func (s *Service) UpdateCustomer(ctx context.Context, id int32, name, email, password string) (*database.Customer, error) {
+	c, err := s.repository.UpdateExistingCustomer(ctx, id, name, email, password)
 	if err != nil {
-		return nil, fmt.Errorf("no customer created %w", err)
+		return nil, fmt.Errorf("update customer: %w", err)
 	}
+	s.publish(ctx, EventUpdated, c.ID)
 	return c, nil
 }
 
-func (s *Service) UpdateCustomer(ctx context.Context, id int32, name, email, password string) (*database.Customer, error) {
-	c, err := s.repository.UpdateExistingCustomer(ctx, id, name, email, password)
+// UpdateCustomerName changes only the name, normalized as on registration.
+// It returns ErrNameRequired for a blank name and ErrNameInvalidUTF8,
+// ErrNameTooLong or ErrNameTooManyBytes for one outside NameLimits.
+func (s *Service) UpdateCustomerName(ctx context.Context, id int32, name string) (*database.Customer, error) {
+	name, err := s.normalizeName(name)
+	if err != nil {
+		return nil, err
+	}
+	c, err := s.repository.UpdateCustomerName(ctx, id, name)
 	if err != nil {
-		return nil, fmt.Errorf("no information has changed %w", err)
+		return nil, fmt.Errorf("update customer name: %w", err)
 	}
+	s.publish(ctx, EventUpdated, c.ID)
+	return c, nil
+}
+
+// TouchCustomer marks the customer as changed without changing any field,
+// for clients that use updated_at or the ETag as an activity signal or to
+// bust caches. It returns the new updated_at.
+func (s *Service) TouchCustomer(ctx context.Context, id int32) (time.Time, error) {
+	updatedAt, err := s.repository.TouchCustomer(ctx, id)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("touch customer: %w", err)
+	}
+	s.publish(ctx, EventUpdated, id)
+	return updatedAt, nil
+}
+
+func (s *Service) SetCustomerActive(ctx context.Context, id int32, active bool) (*database.Customer, error) {
+	c, err := s.repository.SetCustomerActive(ctx, id, active)
+	if err != nil {
+		return nil, fmt.Errorf("set customer active: %w", err)
+	}
+	s.publish(ctx, EventUpdated, c.ID)
+	return c, nil
+}
+
+func (s *Service) DeleteCustomerByID(ctx context.Context, id int32) (*database.Customer, error) {
+	c, err := s.repository.SoftDeleteCustomer(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("delete customer: %w", err)
+	}
+	s.publish(ctx, EventDeleted, c.ID)
 	return c, nil
 }
 
 func (s *Service) DeleteCustomerByEmail(ctx context.Context, email string) error {
-	return s.repository.DeleteCustomerByEmail(ctx, email)
+	deleted, err := s.repository.DeleteCustomerByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		return fmt.Errorf("delete customer: %w", err)
+	}
+	for _, id := range deleted {
+		s.publish(ctx, EventDeleted, id)
+	}
+	return nil
+}
+
+// checkBreachedPassword rejects breached passwords. The check is best effort:
+// if the breach service can't be reached the password is allowed and the failure logged.
+func (s *Service) checkBreachedPassword(ctx context.Context, password string) error {
+	if s.breachChecker == nil {
+		return nil
+	}
+	breached, err := s.breachChecker.IsBreached(ctx, password)
+	if err != nil {
+		log.Println("breached password check skipped:", err)
+		return nil
+	}
+	if breached {
+		return ErrBreachedPassword
+	}
+	return nil
 }
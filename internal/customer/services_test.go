@@ -0,0 +1,161 @@
+package customer_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/clock"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+)
+
+// TestServiceMissingCustomer checks that every ID-based Service method
+// reports a missing customer as ErrCustomerNotFound, whatever wrapping it
+// adds, so handlers can map it to 404 with errors.Is
+func TestServiceMissingCustomer(t *testing.T) {
+	ctx := context.Background()
+	name := "Missing Customer"
+	email := "missing@example.com"
+
+	tests := []struct {
+		name string
+		call func(s *customer.Service) error
+	}{
+		{"GetCustomerByID", func(s *customer.Service) error {
+			_, err := s.GetCustomerByID(ctx, missingID)
+			return err
+		}},
+		{"GetCustomerByEmail", func(s *customer.Service) error {
+			_, err := s.GetCustomerByEmail(ctx, email)
+			return err
+		}},
+		{"GetCustomerByEmailOrID", func(s *customer.Service) error {
+			_, err := s.GetCustomerByEmailOrID(ctx, strconv.Itoa(missingID))
+			return err
+		}},
+		{"UpdateCustomer", func(s *customer.Service) error {
+			_, err := s.UpdateCustomer(ctx, missingID, name, email, "correct-horse-battery")
+			return err
+		}},
+		{"UpdateCustomerName", func(s *customer.Service) error {
+			_, err := s.UpdateCustomerName(ctx, missingID, name)
+			return err
+		}},
+		{"PatchCustomer", func(s *customer.Service) error {
+			_, _, err := s.PatchCustomer(ctx, missingID, customer.PatchInput{Name: &name})
+			return err
+		}},
+		{"TouchCustomer", func(s *customer.Service) error {
+			_, err := s.TouchCustomer(ctx, missingID)
+			return err
+		}},
+		{"SetCustomerActive", func(s *customer.Service) error {
+			_, err := s.SetCustomerActive(ctx, missingID, false)
+			return err
+		}},
+		{"AnonymizeCustomer", func(s *customer.Service) error {
+			_, err := s.AnonymizeCustomer(ctx, missingID)
+			return err
+		}},
+		{"AddTags", func(s *customer.Service) error {
+			_, err := s.AddTags(ctx, missingID, []string{"vip"})
+			return err
+		}},
+		{"RemoveTags", func(s *customer.Service) error {
+			_, err := s.RemoveTags(ctx, missingID, []string{"vip"})
+			return err
+		}},
+		{"RotateAPIToken", func(s *customer.Service) error {
+			_, err := s.RotateAPIToken(ctx, missingID)
+			return err
+		}},
+		{"IssueEmailVerification", func(s *customer.Service) error {
+			_, _, err := s.IssueEmailVerification(ctx, missingID, time.Hour)
+			return err
+		}},
+		{"MergeCustomers", func(s *customer.Service) error {
+			_, err := s.MergeCustomers(ctx, missingID-1, missingID)
+			return err
+		}},
+		{"DeleteCustomerByID", func(s *customer.Service) error {
+			_, err := s.DeleteCustomerByID(ctx, missingID)
+			return err
+		}},
+		{"DeleteCustomerByEmail", func(s *customer.Service) error {
+			return s.DeleteCustomerByEmail(ctx, email)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := customer.NewService(customer.NewMemoryRepository(false), nil, nil, clock.Real{}, nil, 0, customer.NameLimits{})
+			err := tt.call(service)
+			if !errors.Is(err, customer.ErrCustomerNotFound) {
+				t.Fatalf("got %v, want ErrCustomerNotFound", err)
+			}
+		})
+	}
+}
+
+func TestMergeCustomersRevokesMergedCredentials(t *testing.T) {
+	ctx := context.Background()
+	repo := customer.NewMemoryRepository(false)
+	service := customer.NewService(repo, nil, nil, clock.Real{}, nil, 0, customer.NameLimits{})
+	keep, merged := createCustomer(t, repo), createCustomer(t, repo)
+
+	for _, c := range []struct {
+		id   int32
+		hash string
+	}{{keep.ID, "keep-token"}, {merged.ID, "merged-token"}} {
+		if err := repo.SetAPIToken(ctx, c.id, c.hash); err != nil {
+			t.Fatalf("SetAPIToken: %v", err)
+		}
+	}
+	if err := repo.SetEmailVerification(ctx, merged.ID, merged.Email, "merged-verification", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("SetEmailVerification: %v", err)
+	}
+	if err := repo.AddTag(ctx, merged.ID, "vip"); err != nil {
+		t.Fatalf("AddTag: %v", err)
+	}
+
+	if _, err := service.MergeCustomers(ctx, keep.ID, merged.ID); err != nil {
+		t.Fatalf("MergeCustomers: %v", err)
+	}
+
+	assertTags(t, repo, keep.ID, "vip")
+	if id, _, err := repo.FindCustomerIDByAPIToken(ctx, "keep-token"); err != nil || id != keep.ID {
+		t.Errorf("kept customer's token = %d, %v, want %d", id, err, keep.ID)
+	}
+	if id, _, err := repo.FindCustomerIDByAPIToken(ctx, "merged-token"); !errors.Is(err, customer.ErrInvalidAPIToken) {
+		t.Errorf("merged customer's token resolves to %d, %v, want ErrInvalidAPIToken", id, err)
+	}
+	if _, err := repo.FindEmailVerification(ctx, "merged-verification"); !errors.Is(err, customer.ErrInvalidVerificationToken) {
+		t.Errorf("merged customer's pending verification: err = %v, want ErrInvalidVerificationToken", err)
+	}
+}
+
+func TestAuthenticateAPITokenRejectsInactiveCustomer(t *testing.T) {
+	ctx := context.Background()
+	repo := customer.NewMemoryRepository(false)
+	service := customer.NewService(repo, nil, nil, clock.Real{}, nil, 0, customer.NameLimits{})
+	c := createCustomer(t, repo)
+	token, err := service.RotateAPIToken(ctx, c.ID)
+	if err != nil {
+		t.Fatalf("RotateAPIToken: %v", err)
+	}
+	if id, err := service.AuthenticateAPIToken(ctx, token); err != nil || id != c.ID {
+		t.Fatalf("AuthenticateAPIToken = %d, %v, want %d", id, err, c.ID)
+	}
+
+	if _, err := service.SetCustomerActive(ctx, c.ID, false); err != nil {
+		t.Fatalf("SetCustomerActive: %v", err)
+	}
+	if _, err := service.AuthenticateAPIToken(ctx, token); !errors.Is(err, customer.ErrCustomerInactive) {
+		t.Errorf("deactivated customer's token: err = %v, want ErrCustomerInactive", err)
+	}
+	if _, err := service.AuthenticateAPIToken(ctx, "not-a-token"); !errors.Is(err, customer.ErrInvalidAPIToken) {
+		t.Errorf("unknown token: err = %v, want ErrInvalidAPIToken", err)
+	}
+}
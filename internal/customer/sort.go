@@ -0,0 +1,22 @@
+package customer
+
+import "fmt"
+
+// ListSort names the column the customer list is ordered by. Ties are always
+// broken by id so pages stay stable between calls.
+type ListSort string
+
+const (
+	SortByID        ListSort = "id"
+	SortByName      ListSort = "name"
+	SortByCreatedAt ListSort = "created_at"
+)
+
+// ParseListSort validates a sort name coming from config or a query string
+func ParseListSort(s string) (ListSort, error) {
+	switch sort := ListSort(s); sort {
+	case SortByID, SortByName, SortByCreatedAt:
+		return sort, nil
+	}
+	return "", fmt.Errorf("unknown sort %q: must be id, name or created_at", s)
+}
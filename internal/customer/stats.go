@@ -0,0 +1,73 @@
+package customer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+)
+
+// statsCache holds the most recent stats result. Dashboards poll far more
+// often than the stats change, so a result is served from memory for ttl,
+// and concurrent requests that miss share a single query.
+type statsCache struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	stats     *database.GetCustomerStatsRow
+	fetchedAt time.Time
+	// fetching is closed when the query in flight finishes; nil when none is
+	fetching chan struct{}
+}
+
+// GetCustomerStats returns aggregate counts, cached for the stats TTL given
+// to NewService. Writes do not invalidate the cache; results expire instead.
+func (s *Service) GetCustomerStats(ctx context.Context) (*database.GetCustomerStatsRow, error) {
+	if s.stats.ttl <= 0 {
+		return s.fetchCustomerStats(ctx)
+	}
+	for {
+		s.stats.mu.Lock()
+		if s.stats.stats != nil && s.clock.Now().Sub(s.stats.fetchedAt) < s.stats.ttl {
+			stats := s.stats.stats
+			s.stats.mu.Unlock()
+			return stats, nil
+		}
+		if fetching := s.stats.fetching; fetching != nil {
+			s.stats.mu.Unlock()
+			// Wait for the query in flight, then look again; if it failed,
+			// one of the waiters runs the next one
+			select {
+			case <-fetching:
+				continue
+			case <-ctx.Done():
+				return nil, fmt.Errorf("get customer stats: %w", ctx.Err())
+			}
+		}
+		fetching := make(chan struct{})
+		s.stats.fetching = fetching
+		s.stats.mu.Unlock()
+
+		stats, err := s.fetchCustomerStats(ctx)
+
+		s.stats.mu.Lock()
+		if err == nil {
+			s.stats.stats = stats
+			s.stats.fetchedAt = s.clock.Now()
+		}
+		s.stats.fetching = nil
+		close(fetching)
+		s.stats.mu.Unlock()
+		return stats, err
+	}
+}
+
+func (s *Service) fetchCustomerStats(ctx context.Context) (*database.GetCustomerStatsRow, error) {
+	stats, err := s.repository.GetCustomerStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get customer stats: %w", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,65 @@
+package customer
+
+import (
+	"context"
+	"time"
+
+	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+)
+
+// Store is the persistence the Service is written against. *Repository is
+// the implementation used today, over Postgres or, with
+// NewMemoryRepository, over memory; a backend whose queries do not fit
+// database.Querier can implement Store directly instead.
+//
+// Implementations report missing customers with ErrCustomerNotFound and
+// unique conflicts with ErrEmailAlreadyExists, ErrNameAlreadyExists or
+// ErrAlreadyExists, as the Service maps those to client errors.
+type Store interface {
+	// RunInTx runs fn with a Store whose calls share one transaction,
+	// committing when fn succeeds and rolling back otherwise
+	RunInTx(ctx context.Context, fn func(tx Store) error) error
+
+	// Reads
+	FindAllCustomers(ctx context.Context, filter ListFilter) ([]database.Customer, error)
+	CountCustomers(ctx context.Context, filter ListFilter) (int64, error)
+	SearchCustomers(ctx context.Context, query string, limit, offset int32) ([]database.Customer, error)
+	CountSearchCustomers(ctx context.Context, query string) (int64, error)
+	GetCustomerStats(ctx context.Context) (*database.GetCustomerStatsRow, error)
+	CountSignupsByDay(ctx context.Context, since, until time.Time) ([]database.CountSignupsByDayRow, error)
+	IterateCustomers(ctx context.Context, batchSize int, fn func([]database.Customer) error) error
+	FindCustomerByID(ctx context.Context, id int32) (*database.Customer, error)
+	ExistsByID(ctx context.Context, id int32) (bool, error)
+	GetCustomerByIDForUpdate(ctx context.Context, id int32) (*database.Customer, error)
+	FindCustomerByEmail(ctx context.Context, email string) (*database.Customer, error)
+	FindCustomersByEmails(ctx context.Context, emails []string) (map[string]database.Customer, error)
+
+	// Writes
+	CreateNewCustomer(ctx context.Context, name, email, password, phone, avatarURL string) (*database.Customer, error)
+	UpsertCustomerByEmail(ctx context.Context, name, email, password, phone, avatarURL string) (c *database.Customer, created bool, err error)
+	TouchCustomer(ctx context.Context, id int32) (time.Time, error)
+	UpdateExistingCustomer(ctx context.Context, id int32, name, email, password string) (*database.Customer, error)
+	UpdateCustomerName(ctx context.Context, id int32, name string) (*database.Customer, error)
+	PatchCustomer(ctx context.Context, id int32, name, email, password, phone, avatarURL *string, expectedUpdatedAt *time.Time) (c *database.Customer, changed bool, err error)
+	SetCustomerActive(ctx context.Context, id int32, active bool) (*database.Customer, error)
+	SetCustomersActive(ctx context.Context, ids []int32, active bool) ([]int32, error)
+	UpdateLastLogin(ctx context.Context, id int32) error
+	SoftDeleteCustomer(ctx context.Context, id int32) (*database.Customer, error)
+	AnonymizeCustomer(ctx context.Context, id int32, name, email, password string) (*database.Customer, error)
+	DeleteCustomerByEmail(ctx context.Context, email string) ([]int32, error)
+
+	// Tags, API tokens and email verifications
+	AddTag(ctx context.Context, id int32, tag string) error
+	RemoveTag(ctx context.Context, id int32, tag string) error
+	ListTags(ctx context.Context, id int32) ([]string, error)
+	MoveTags(ctx context.Context, fromID, toID int32) error
+	SetAPIToken(ctx context.Context, id int32, tokenHash string) error
+	DeleteAPIToken(ctx context.Context, id int32) error
+	FindCustomerIDByAPIToken(ctx context.Context, tokenHash string) (id int32, active bool, err error)
+	SetEmailVerification(ctx context.Context, id int32, email, tokenHash string, expiresAt time.Time) error
+	FindEmailVerification(ctx context.Context, tokenHash string) (*database.CustomerEmailVerification, error)
+	DeleteEmailVerification(ctx context.Context, id int32) error
+	MarkEmailVerified(ctx context.Context, id int32, email string) (*database.Customer, error)
+}
+
+var _ Store = (*Repository)(nil)
@@ -0,0 +1,638 @@
+package customer_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer/customertest"
+	dbcheck "github.com/Amir-Golmoradi/Customer-Management-System/internal/database"
+	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// missingID is an ID no test customer gets
+const missingID = math.MaxInt32
+
+var errAbort = errors.New("abort")
+
+// newStoreFunc returns an empty Store for one test; on Postgres "empty" means
+// a fresh transaction, so tests only rely on rows they created themselves
+type newStoreFunc func(t *testing.T) customer.Store
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, func(t *testing.T) customer.Store {
+		return customer.NewMemoryRepository(false)
+	})
+}
+
+func TestPostgresStore(t *testing.T) {
+	pool := customertest.OpenPool(t)
+	testStore(t, func(t *testing.T) customer.Store {
+		return customertest.NewRepository(t, pool)
+	})
+}
+
+// testStore is the conformance suite every Store backend must pass
+func testStore(t *testing.T, newStore newStoreFunc) {
+	ctx := context.Background()
+
+	t.Run("create and find", func(t *testing.T) {
+		s := newStore(t)
+		c := createCustomer(t, s)
+		if !c.IsActive || c.EmailVerified || c.Phone.Valid || c.AvatarUrl.Valid {
+			t.Errorf("new customer = %+v, want active, unverified, no phone or avatar", c)
+		}
+
+		byID, err := s.FindCustomerByID(ctx, c.ID)
+		if err != nil || byID.Email != c.Email {
+			t.Fatalf("FindCustomerByID = %v, %v", byID, err)
+		}
+		byEmail, err := s.FindCustomerByEmail(ctx, strings.ToUpper(c.Email))
+		if err != nil || byEmail.ID != c.ID {
+			t.Fatalf("FindCustomerByEmail in upper case = %v, %v", byEmail, err)
+		}
+		exists, err := s.ExistsByID(ctx, c.ID)
+		if err != nil || !exists {
+			t.Fatalf("ExistsByID = %v, %v, want true", exists, err)
+		}
+		found, err := s.FindCustomersByEmails(ctx, []string{strings.ToUpper(c.Email), uniqueEmail("nobody")})
+		if err != nil || len(found) != 1 || found[c.Email].ID != c.ID {
+			t.Fatalf("FindCustomersByEmails = %v, %v", found, err)
+		}
+	})
+
+	t.Run("soft delete hides the customer", func(t *testing.T) {
+		s := newStore(t)
+		c := createCustomer(t, s)
+		deleted, err := s.SoftDeleteCustomer(ctx, c.ID)
+		if err != nil || !deleted.DeletedAt.Valid {
+			t.Fatalf("SoftDeleteCustomer = %v, %v", deleted, err)
+		}
+		if _, err := s.FindCustomerByID(ctx, c.ID); !errors.Is(err, customer.ErrCustomerNotFound) {
+			t.Errorf("FindCustomerByID after delete: err = %v, want ErrCustomerNotFound", err)
+		}
+		if _, err := s.FindCustomerByEmail(ctx, c.Email); !errors.Is(err, customer.ErrCustomerNotFound) {
+			t.Errorf("FindCustomerByEmail after delete: err = %v, want ErrCustomerNotFound", err)
+		}
+		if exists, err := s.ExistsByID(ctx, c.ID); err != nil || exists {
+			t.Errorf("ExistsByID after delete = %v, %v, want false", exists, err)
+		}
+	})
+
+	t.Run("upsert creates then updates", func(t *testing.T) {
+		s := newStore(t)
+		email := uniqueEmail("upsert")
+		first, created, err := s.UpsertCustomerByEmail(ctx, uniqueName("First"), email, "hash", "", "")
+		if err != nil || !created {
+			t.Fatalf("first upsert = %v, %v, %v, want created", first, created, err)
+		}
+		name := uniqueName("Second")
+		second, created, err := s.UpsertCustomerByEmail(ctx, name, strings.ToUpper(email), "hash", "", "")
+		if err != nil || created {
+			t.Fatalf("second upsert = %v, %v, %v, want updated", second, created, err)
+		}
+		if second.ID != first.ID || second.Name != name {
+			t.Errorf("second upsert = %+v, want customer %d renamed to %q", second, first.ID, name)
+		}
+	})
+
+	t.Run("patch", func(t *testing.T) {
+		s := newStore(t)
+		c := createCustomer(t, s)
+		same, changed, err := s.PatchCustomer(ctx, c.ID, &c.Name, nil, nil, nil, nil, nil)
+		if err != nil || changed || same.ID != c.ID {
+			t.Fatalf("no-op patch = %v, %v, %v, want unchanged customer", same, changed, err)
+		}
+		name := uniqueName("Patched")
+		phone := "+15551234567"
+		patched, changed, err := s.PatchCustomer(ctx, c.ID, &name, nil, nil, &phone, nil, &c.UpdatedAt.Time)
+		if err != nil || !changed || patched.Name != name || patched.Phone.String != phone {
+			t.Fatalf("patch = %v, %v, %v", patched, changed, err)
+		}
+		stale := c.UpdatedAt.Time.Add(-time.Hour)
+		other := uniqueName("Stale")
+		if _, _, err := s.PatchCustomer(ctx, c.ID, &other, nil, nil, nil, nil, &stale); !errors.Is(err, customer.ErrStaleCustomer) {
+			t.Errorf("stale patch: err = %v, want ErrStaleCustomer", err)
+		}
+	})
+
+	t.Run("touch", func(t *testing.T) {
+		s := newStore(t)
+		c := createCustomer(t, s)
+		updatedAt, err := s.TouchCustomer(ctx, c.ID)
+		if err != nil || updatedAt.Before(c.UpdatedAt.Time) {
+			t.Fatalf("TouchCustomer = %v, %v, want no earlier than %v", updatedAt, err, c.UpdatedAt.Time)
+		}
+		touched, err := s.FindCustomerByID(ctx, c.ID)
+		if err != nil || !touched.UpdatedAt.Time.Equal(updatedAt) {
+			t.Errorf("updated_at after touch = %v, %v, want %v", touched.UpdatedAt.Time, err, updatedAt)
+		}
+	})
+
+	t.Run("active status", func(t *testing.T) {
+		s := newStore(t)
+		c := createCustomer(t, s)
+		deactivated, err := s.SetCustomerActive(ctx, c.ID, false)
+		if err != nil || deactivated.IsActive {
+			t.Fatalf("SetCustomerActive(false) = %v, %v", deactivated, err)
+		}
+		updated, err := s.SetCustomersActive(ctx, []int32{c.ID, missingID}, true)
+		if err != nil || !slices.Equal(updated, []int32{c.ID}) {
+			t.Fatalf("SetCustomersActive = %v, %v, want [%d]", updated, err, c.ID)
+		}
+	})
+
+	t.Run("tags", func(t *testing.T) {
+		s := newStore(t)
+		from, to := createCustomer(t, s), createCustomer(t, s)
+		for _, tag := range []string{"vip", "beta", "vip"} {
+			if err := s.AddTag(ctx, from.ID, tag); err != nil {
+				t.Fatalf("AddTag(%q): %v", tag, err)
+			}
+		}
+		assertTags(t, s, from.ID, "beta", "vip")
+		if err := s.RemoveTag(ctx, from.ID, "beta"); err != nil {
+			t.Fatalf("RemoveTag: %v", err)
+		}
+		if err := s.AddTag(ctx, to.ID, "vip"); err != nil {
+			t.Fatalf("AddTag: %v", err)
+		}
+		if err := s.AddTag(ctx, from.ID, "gold"); err != nil {
+			t.Fatalf("AddTag: %v", err)
+		}
+		if err := s.MoveTags(ctx, from.ID, to.ID); err != nil {
+			t.Fatalf("MoveTags: %v", err)
+		}
+		assertTags(t, s, from.ID)
+		assertTags(t, s, to.ID, "gold", "vip")
+	})
+
+	t.Run("run in tx", func(t *testing.T) {
+		s := newStore(t)
+		rolledBack := uniqueEmail("rolled-back")
+		err := s.RunInTx(ctx, func(tx customer.Store) error {
+			if _, err := tx.CreateNewCustomer(ctx, uniqueName("Rolled Back"), rolledBack, "hash", "", ""); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Fatalf("RunInTx = %v, want %v", err, errAbort)
+		}
+		if _, err := s.FindCustomerByEmail(ctx, rolledBack); !errors.Is(err, customer.ErrCustomerNotFound) {
+			t.Errorf("customer from a rolled back transaction: err = %v, want ErrCustomerNotFound", err)
+		}
+
+		committed := uniqueEmail("committed")
+		err = s.RunInTx(ctx, func(tx customer.Store) error {
+			_, err := tx.CreateNewCustomer(ctx, uniqueName("Committed"), committed, "hash", "", "")
+			return err
+		})
+		if err != nil {
+			t.Fatalf("RunInTx: %v", err)
+		}
+		if _, err := s.FindCustomerByEmail(ctx, committed); err != nil {
+			t.Errorf("customer from a committed transaction: %v", err)
+		}
+	})
+
+	t.Run("email verification", func(t *testing.T) {
+		s := newStore(t)
+		c := createCustomer(t, s)
+		hash := fmt.Sprintf("hash-%d", nextID.Add(1))
+		if err := s.SetEmailVerification(ctx, c.ID, c.Email, hash, time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("SetEmailVerification: %v", err)
+		}
+		verification, err := s.FindEmailVerification(ctx, hash)
+		if err != nil || verification.CustomerID != c.ID || verification.Email != c.Email {
+			t.Fatalf("FindEmailVerification = %+v, %v", verification, err)
+		}
+		if _, err := s.MarkEmailVerified(ctx, c.ID, uniqueEmail("other")); !errors.Is(err, customer.ErrCustomerNotFound) {
+			t.Errorf("MarkEmailVerified for another email: err = %v, want ErrCustomerNotFound", err)
+		}
+		verified, err := s.MarkEmailVerified(ctx, c.ID, strings.ToUpper(c.Email))
+		if err != nil || !verified.EmailVerified {
+			t.Fatalf("MarkEmailVerified = %v, %v", verified, err)
+		}
+		if err := s.DeleteEmailVerification(ctx, c.ID); err != nil {
+			t.Fatalf("DeleteEmailVerification: %v", err)
+		}
+		if _, err := s.FindEmailVerification(ctx, hash); !errors.Is(err, customer.ErrInvalidVerificationToken) {
+			t.Errorf("FindEmailVerification after delete: err = %v, want ErrInvalidVerificationToken", err)
+		}
+	})
+
+	t.Run("api token", func(t *testing.T) {
+		s := newStore(t)
+		c := createCustomer(t, s)
+		hash := fmt.Sprintf("hash-%d", nextID.Add(1))
+		if err := s.SetAPIToken(ctx, c.ID, hash); err != nil {
+			t.Fatalf("SetAPIToken: %v", err)
+		}
+		if id, active, err := s.FindCustomerIDByAPIToken(ctx, hash); err != nil || id != c.ID || !active {
+			t.Fatalf("FindCustomerIDByAPIToken = %d, %v, %v, want %d, true", id, active, err, c.ID)
+		}
+		if _, err := s.SetCustomerActive(ctx, c.ID, false); err != nil {
+			t.Fatalf("SetCustomerActive: %v", err)
+		}
+		if id, active, err := s.FindCustomerIDByAPIToken(ctx, hash); err != nil || id != c.ID || active {
+			t.Fatalf("FindCustomerIDByAPIToken for a deactivated customer = %d, %v, %v, want %d, false", id, active, err, c.ID)
+		}
+		rotated := fmt.Sprintf("hash-%d", nextID.Add(1))
+		if err := s.SetAPIToken(ctx, c.ID, rotated); err != nil {
+			t.Fatalf("SetAPIToken: %v", err)
+		}
+		if _, _, err := s.FindCustomerIDByAPIToken(ctx, hash); !errors.Is(err, customer.ErrInvalidAPIToken) {
+			t.Errorf("FindCustomerIDByAPIToken with a rotated token: err = %v, want ErrInvalidAPIToken", err)
+		}
+		if _, err := s.SoftDeleteCustomer(ctx, c.ID); err != nil {
+			t.Fatalf("SoftDeleteCustomer: %v", err)
+		}
+		if _, _, err := s.FindCustomerIDByAPIToken(ctx, rotated); !errors.Is(err, customer.ErrInvalidAPIToken) {
+			t.Errorf("FindCustomerIDByAPIToken for a deleted customer: err = %v, want ErrInvalidAPIToken", err)
+		}
+	})
+
+	t.Run("search", func(t *testing.T) {
+		s := newStore(t)
+		c := createCustomer(t, s)
+		customers, err := s.SearchCustomers(ctx, c.Name, 10, 0)
+		if err != nil || len(customers) != 1 || customers[0].ID != c.ID {
+			t.Fatalf("SearchCustomers = %v, %v", customers, err)
+		}
+		total, err := s.CountSearchCustomers(ctx, c.Name)
+		if err != nil || total != 1 {
+			t.Fatalf("CountSearchCustomers = %d, %v, want 1", total, err)
+		}
+	})
+
+	t.Run("iterate", func(t *testing.T) {
+		s := newStore(t)
+		want := []int32{createCustomer(t, s).ID, createCustomer(t, s).ID, createCustomer(t, s).ID}
+		var seen []int32
+		err := s.IterateCustomers(ctx, 2, func(batch []database.Customer) error {
+			if len(batch) > 2 {
+				t.Errorf("batch of %d customers, want at most 2", len(batch))
+			}
+			for _, c := range batch {
+				seen = append(seen, c.ID)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("IterateCustomers: %v", err)
+		}
+		for _, id := range want {
+			if !slices.Contains(seen, id) {
+				t.Errorf("IterateCustomers skipped customer %d", id)
+			}
+		}
+	})
+
+	t.Run("anonymize", func(t *testing.T) {
+		s := newStore(t)
+		c := createCustomer(t, s)
+		anonymized, err := s.AnonymizeCustomer(ctx, c.ID, "Deleted customer", uniqueEmail("anonymized"), "hash")
+		if err != nil || !anonymized.DeletedAt.Valid || anonymized.IsActive {
+			t.Fatalf("AnonymizeCustomer = %v, %v", anonymized, err)
+		}
+		if _, err := s.FindCustomerByID(ctx, c.ID); !errors.Is(err, customer.ErrCustomerNotFound) {
+			t.Errorf("FindCustomerByID after anonymizing: err = %v, want ErrCustomerNotFound", err)
+		}
+	})
+
+	t.Run("delete by email", func(t *testing.T) {
+		s := newStore(t)
+		c := createCustomer(t, s)
+		deleted, err := s.DeleteCustomerByEmail(ctx, strings.ToUpper(c.Email))
+		if err != nil || !slices.Equal(deleted, []int32{c.ID}) {
+			t.Fatalf("DeleteCustomerByEmail = %v, %v, want [%d]", deleted, err, c.ID)
+		}
+		if _, err := s.DeleteCustomerByEmail(ctx, c.Email); !errors.Is(err, customer.ErrCustomerNotFound) {
+			t.Errorf("second DeleteCustomerByEmail: err = %v, want ErrCustomerNotFound", err)
+		}
+	})
+}
+
+// nextID keeps names and emails unique across tests sharing a database
+var nextID atomic.Int64
+
+func uniqueName(prefix string) string {
+	return fmt.Sprintf("%s %d-%d", prefix, time.Now().UnixNano(), nextID.Add(1))
+}
+
+func uniqueEmail(local string) string {
+	return fmt.Sprintf("%s-%d-%d@example.com", local, time.Now().UnixNano(), nextID.Add(1))
+}
+
+func createCustomer(t *testing.T, s customer.Store) *database.Customer {
+	t.Helper()
+	c, err := s.CreateNewCustomer(context.Background(), uniqueName("Customer"), uniqueEmail("customer"), "hash", "", "")
+	if err != nil {
+		t.Fatalf("create customer: %v", err)
+	}
+	return c
+}
+
+func assertTags(t *testing.T, s customer.Store, id int32, want ...string) {
+	t.Helper()
+	got, err := s.ListTags(context.Background(), id)
+	if err != nil {
+		t.Fatalf("ListTags: %v", err)
+	}
+	if len(got) != len(want) || !slices.Equal(got, want) {
+		t.Errorf("tags of customer %d = %v, want %v", id, got, want)
+	}
+}
+
+func TestMemoryStoreErrors(t *testing.T) {
+	testStoreErrors(t, func(t *testing.T) customer.Store {
+		return customer.NewMemoryRepository(false)
+	})
+}
+
+func TestPostgresStoreErrors(t *testing.T) {
+	pool := customertest.OpenPool(t)
+	testStoreErrors(t, func(t *testing.T) customer.Store {
+		return customertest.NewRepository(t, pool)
+	})
+}
+
+func TestMemoryStoreUniqueNames(t *testing.T) {
+	testUniqueNames(t, func(t *testing.T) customer.Store {
+		return customer.NewMemoryRepository(true)
+	})
+}
+
+func TestPostgresStoreUniqueNames(t *testing.T) {
+	pool := customertest.OpenPool(t)
+	if err := dbcheck.CheckUniqueNameIndex(context.Background(), pool); err != nil {
+		t.Skip(err)
+	}
+	testUniqueNames(t, func(t *testing.T) customer.Store {
+		return customertest.NewRepository(t, pool)
+	})
+}
+
+// TestPostgresGetCustomerByIDForUpdateLocks shows that the row stays locked
+// until the RunInTx holding it commits. The customer is committed, unlike the
+// rows of other tests, so a second connection can see and wait for it.
+func TestPostgresGetCustomerByIDForUpdateLocks(t *testing.T) {
+	pool := customertest.OpenPool(t)
+	ctx := context.Background()
+	repo := customer.NewCustomerRepository(pool, database.New(pool))
+	c, err := repo.CreateNewCustomer(ctx, uniqueName("Locked"), uniqueEmail("locked"), "hash", "", "")
+	if err != nil {
+		t.Fatalf("CreateNewCustomer: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := pool.Exec(ctx, "DELETE FROM customers WHERE id = $1", c.ID); err != nil {
+			t.Errorf("delete locked customer: %v", err)
+		}
+	})
+
+	err = repo.RunInTx(ctx, func(tx customer.Store) error {
+		if _, err := tx.GetCustomerByIDForUpdate(ctx, c.ID); err != nil {
+			return fmt.Errorf("lock: %w", err)
+		}
+
+		other, err := pool.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer other.Rollback(ctx)
+		if _, err := other.Exec(ctx, "SET LOCAL lock_timeout = '200ms'"); err != nil {
+			return err
+		}
+		var pgErr *pgconn.PgError
+		_, err = repo.WithTx(other).GetCustomerByIDForUpdate(ctx, c.ID)
+		if !errors.As(err, &pgErr) || pgErr.Code != "55P03" {
+			t.Errorf("second GetCustomerByIDForUpdate while locked: err = %v, want lock_not_available", err)
+		}
+		if _, err := repo.FindCustomerByID(ctx, c.ID); err != nil {
+			t.Errorf("plain read while locked: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunInTx holding the lock: %v", err)
+	}
+
+	err = repo.RunInTx(ctx, func(tx customer.Store) error {
+		_, err := tx.GetCustomerByIDForUpdate(ctx, c.ID)
+		return err
+	})
+	if err != nil {
+		t.Errorf("GetCustomerByIDForUpdate after the commit: %v", err)
+	}
+}
+
+// testStoreErrors pins the error contract of Store: the Service maps these
+// sentinels to client errors, so every backend must return the same one in
+// the same case
+func testStoreErrors(t *testing.T, newStore newStoreFunc) {
+	ctx := context.Background()
+	name, email, phone := uniqueName("Patched"), uniqueEmail("patched"), "+15551234567"
+
+	notFound := []struct {
+		name string
+		// call acts on customer id, which holds the email held, as
+		// MarkEmailVerified only matches a customer's own email
+		call func(s customer.Store, id int32, held string) error
+		// anonymizing also applies to deleted customers
+		deletedOK bool
+	}{
+		{name: "FindCustomerByID", call: func(s customer.Store, id int32, held string) error {
+			_, err := s.FindCustomerByID(ctx, id)
+			return err
+		}},
+		{name: "GetCustomerByIDForUpdate", call: func(s customer.Store, id int32, held string) error {
+			_, err := s.GetCustomerByIDForUpdate(ctx, id)
+			return err
+		}},
+		{name: "TouchCustomer", call: func(s customer.Store, id int32, held string) error {
+			_, err := s.TouchCustomer(ctx, id)
+			return err
+		}},
+		{name: "UpdateExistingCustomer", call: func(s customer.Store, id int32, held string) error {
+			_, err := s.UpdateExistingCustomer(ctx, id, name, email, "hash")
+			return err
+		}},
+		{name: "UpdateCustomerName", call: func(s customer.Store, id int32, held string) error {
+			_, err := s.UpdateCustomerName(ctx, id, name)
+			return err
+		}},
+		{name: "PatchCustomer", call: func(s customer.Store, id int32, held string) error {
+			_, _, err := s.PatchCustomer(ctx, id, &name, nil, nil, &phone, nil, nil)
+			return err
+		}},
+		{name: "SetCustomerActive", call: func(s customer.Store, id int32, held string) error {
+			_, err := s.SetCustomerActive(ctx, id, false)
+			return err
+		}},
+		{name: "SoftDeleteCustomer", call: func(s customer.Store, id int32, held string) error {
+			_, err := s.SoftDeleteCustomer(ctx, id)
+			return err
+		}},
+		{name: "AnonymizeCustomer", deletedOK: true, call: func(s customer.Store, id int32, held string) error {
+			_, err := s.AnonymizeCustomer(ctx, id, "Deleted customer", uniqueEmail("anonymized"), "hash")
+			return err
+		}},
+		{name: "MarkEmailVerified", call: func(s customer.Store, id int32, held string) error {
+			_, err := s.MarkEmailVerified(ctx, id, held)
+			return err
+		}},
+	}
+	for _, tt := range notFound {
+		t.Run(tt.name+" on a missing customer", func(t *testing.T) {
+			s := newStore(t)
+			if err := tt.call(s, missingID, uniqueEmail("missing")); !errors.Is(err, customer.ErrCustomerNotFound) {
+				t.Errorf("err = %v, want ErrCustomerNotFound", err)
+			}
+		})
+		if tt.deletedOK {
+			continue
+		}
+		t.Run(tt.name+" on a deleted customer", func(t *testing.T) {
+			s := newStore(t)
+			c := createCustomer(t, s)
+			if _, err := s.SoftDeleteCustomer(ctx, c.ID); err != nil {
+				t.Fatalf("SoftDeleteCustomer: %v", err)
+			}
+			if err := tt.call(s, c.ID, c.Email); !errors.Is(err, customer.ErrCustomerNotFound) {
+				t.Errorf("err = %v, want ErrCustomerNotFound", err)
+			}
+		})
+	}
+
+	t.Run("email lookups on a missing customer", func(t *testing.T) {
+		s := newStore(t)
+		missing := uniqueEmail("missing")
+		if _, err := s.FindCustomerByEmail(ctx, missing); !errors.Is(err, customer.ErrCustomerNotFound) {
+			t.Errorf("FindCustomerByEmail: err = %v, want ErrCustomerNotFound", err)
+		}
+		if _, err := s.DeleteCustomerByEmail(ctx, missing); !errors.Is(err, customer.ErrCustomerNotFound) {
+			t.Errorf("DeleteCustomerByEmail: err = %v, want ErrCustomerNotFound", err)
+		}
+		if _, err := s.FindEmailVerification(ctx, "unknown-token-hash"); !errors.Is(err, customer.ErrInvalidVerificationToken) {
+			t.Errorf("FindEmailVerification: err = %v, want ErrInvalidVerificationToken", err)
+		}
+	})
+
+	emailConflicts := []struct {
+		name string
+		// call uses the email taken by another customer, on customer id
+		call func(s customer.Store, id int32, taken string) error
+	}{
+		{"CreateNewCustomer", func(s customer.Store, id int32, taken string) error {
+			_, err := s.CreateNewCustomer(ctx, uniqueName("Duplicate"), taken, "hash", "", "")
+			return err
+		}},
+		{"UpdateExistingCustomer", func(s customer.Store, id int32, taken string) error {
+			_, err := s.UpdateExistingCustomer(ctx, id, uniqueName("Duplicate"), taken, "hash")
+			return err
+		}},
+		{"PatchCustomer", func(s customer.Store, id int32, taken string) error {
+			_, _, err := s.PatchCustomer(ctx, id, nil, &taken, nil, nil, nil, nil)
+			return err
+		}},
+	}
+	for _, tt := range emailConflicts {
+		for _, variant := range []struct {
+			name   string
+			taken  func(email string) string
+			delete bool
+		}{
+			{"taken", func(email string) string { return email }, false},
+			{"taken in another case", strings.ToUpper, false},
+			{"held by a deleted customer", func(email string) string { return email }, true},
+		} {
+			t.Run(tt.name+" with an email "+variant.name, func(t *testing.T) {
+				s := newStore(t)
+				holder, c := createCustomer(t, s), createCustomer(t, s)
+				if variant.delete {
+					if _, err := s.SoftDeleteCustomer(ctx, holder.ID); err != nil {
+						t.Fatalf("SoftDeleteCustomer: %v", err)
+					}
+				}
+				// The savepoint keeps the Postgres test transaction usable
+				err := s.RunInTx(ctx, func(tx customer.Store) error {
+					return tt.call(tx, c.ID, variant.taken(holder.Email))
+				})
+				if !errors.Is(err, customer.ErrEmailAlreadyExists) {
+					t.Errorf("err = %v, want ErrEmailAlreadyExists", err)
+				}
+			})
+		}
+	}
+
+	t.Run("UpsertCustomerByEmail with an email held by a deleted customer", func(t *testing.T) {
+		s := newStore(t)
+		holder := createCustomer(t, s)
+		if _, err := s.SoftDeleteCustomer(ctx, holder.ID); err != nil {
+			t.Fatalf("SoftDeleteCustomer: %v", err)
+		}
+		_, _, err := s.UpsertCustomerByEmail(ctx, uniqueName("Upsert"), holder.Email, "hash", "", "")
+		if !errors.Is(err, customer.ErrEmailAlreadyExists) {
+			t.Errorf("err = %v, want ErrEmailAlreadyExists", err)
+		}
+	})
+}
+
+// testUniqueNames pins the name conflicts of a Store that requires unique
+// names, as with REQUIRE_UNIQUE_NAME
+func testUniqueNames(t *testing.T, newStore newStoreFunc) {
+	ctx := context.Background()
+	conflicts := []struct {
+		name string
+		call func(s customer.Store, id int32, taken string) error
+	}{
+		{"CreateNewCustomer", func(s customer.Store, id int32, taken string) error {
+			_, err := s.CreateNewCustomer(ctx, taken, uniqueEmail("duplicate"), "hash", "", "")
+			return err
+		}},
+		{"UpdateExistingCustomer", func(s customer.Store, id int32, taken string) error {
+			_, err := s.UpdateExistingCustomer(ctx, id, taken, uniqueEmail("duplicate"), "hash")
+			return err
+		}},
+		{"UpdateCustomerName", func(s customer.Store, id int32, taken string) error {
+			_, err := s.UpdateCustomerName(ctx, id, taken)
+			return err
+		}},
+		{"PatchCustomer", func(s customer.Store, id int32, taken string) error {
+			_, _, err := s.PatchCustomer(ctx, id, &taken, nil, nil, nil, nil, nil)
+			return err
+		}},
+	}
+	for _, tt := range conflicts {
+		t.Run(tt.name+" with a taken name", func(t *testing.T) {
+			s := newStore(t)
+			holder, c := createCustomer(t, s), createCustomer(t, s)
+			err := s.RunInTx(ctx, func(tx customer.Store) error {
+				return tt.call(tx, c.ID, holder.Name)
+			})
+			if !errors.Is(err, customer.ErrNameAlreadyExists) {
+				t.Errorf("err = %v, want ErrNameAlreadyExists", err)
+			}
+		})
+	}
+
+	t.Run("a deleted customer's name is free", func(t *testing.T) {
+		s := newStore(t)
+		holder := createCustomer(t, s)
+		if _, err := s.SoftDeleteCustomer(ctx, holder.ID); err != nil {
+			t.Fatalf("SoftDeleteCustomer: %v", err)
+		}
+		if _, err := s.CreateNewCustomer(ctx, holder.Name, uniqueEmail("reused"), "hash", "", ""); err != nil {
+			t.Errorf("CreateNewCustomer with a deleted customer's name: %v", err)
+		}
+	})
+}
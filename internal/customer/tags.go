@@ -0,0 +1,70 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidTag = errors.New("tags must be 1-50 characters of lowercase letters, digits, '-' or '_'")
+	ErrNoTags     = errors.New("at least one tag is required")
+)
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9_-]{1,50}$`)
+
+// normalizeTag trims and lowercases a tag so "VIP" and "vip" are the same label
+func normalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if !tagPattern.MatchString(tag) {
+		return "", ErrInvalidTag
+	}
+	return tag, nil
+}
+
+// AddTags attaches every tag to the customer in one transaction and returns
+// the customer's resulting tags
+func (s *Service) AddTags(ctx context.Context, id int32, tags []string) ([]string, error) {
+	return s.updateTags(ctx, id, tags, Store.AddTag)
+}
+
+// RemoveTags detaches every tag from the customer in one transaction and
+// returns the customer's remaining tags
+func (s *Service) RemoveTags(ctx context.Context, id int32, tags []string) ([]string, error) {
+	return s.updateTags(ctx, id, tags, Store.RemoveTag)
+}
+
+func (s *Service) updateTags(ctx context.Context, id int32, tags []string, apply func(Store, context.Context, int32, string) error) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, ErrNoTags
+	}
+	normalized := make([]string, len(tags))
+	for i, tag := range tags {
+		var err error
+		if normalized[i], err = normalizeTag(tag); err != nil {
+			return nil, err
+		}
+	}
+
+	var current []string
+	err := s.repository.RunInTx(ctx, func(tx Store) error {
+		if _, err := tx.GetCustomerByIDForUpdate(ctx, id); err != nil {
+			return err
+		}
+		for _, tag := range normalized {
+			if err := apply(tx, ctx, id, tag); err != nil {
+				return err
+			}
+		}
+		var err error
+		current, err = tx.ListTags(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("update customer tags: %w", err)
+	}
+	s.publish(ctx, EventUpdated, id)
+	return current, nil
+}
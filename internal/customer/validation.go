@@ -0,0 +1,51 @@
+package customer
+
+import "strings"
+
+// FieldError is the reason one field of an input was rejected. It unwraps
+// to the sentinel, such as ErrInvalidEmail.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e FieldError) Error() string { return e.Field + ": " + e.Err.Error() }
+
+func (e FieldError) Unwrap() error { return e.Err }
+
+// ValidationError reports every rejected field of an input at once, so a
+// form can highlight them all. errors.Is matches any of their sentinels.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Fields))
+	for i, field := range e.Fields {
+		errs[i] = field
+	}
+	return errs
+}
+
+// check records err against field unless it is nil
+func (e *ValidationError) check(field string, err error) {
+	if err != nil {
+		e.Fields = append(e.Fields, FieldError{Field: field, Err: err})
+	}
+}
+
+// errOrNil returns e when any field was rejected, and nil otherwise
+func (e *ValidationError) errOrNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,79 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+)
+
+var (
+	// ErrInvalidVerificationToken covers unknown, expired and already used
+	// tokens alike, so the response does not tell them apart
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+	ErrEmailAlreadyVerified     = errors.New("email is already verified")
+	ErrEmailNotVerified         = errors.New("email is not verified")
+)
+
+// IssueEmailVerification creates a token proving ownership of the customer's
+// current email, valid for ttl. It replaces any pending token, so only the
+// newest one works, and is bound to the email, so it stops working once the
+// customer changes it. The plaintext is returned only here and is never stored;
+// delivering it to the customer is up to the caller.
+func (s *Service) IssueEmailVerification(ctx context.Context, id int32, ttl time.Duration) (string, time.Time, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("generate verification token: %w", err)
+	}
+	expiresAt := s.clock.Now().Add(ttl)
+
+	err = s.repository.RunInTx(ctx, func(tx Store) error {
+		c, err := tx.GetCustomerByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if c.EmailVerified {
+			return ErrEmailAlreadyVerified
+		}
+		return tx.SetEmailVerification(ctx, id, c.Email, hashToken(token), expiresAt)
+	})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("issue email verification: %w", err)
+	}
+	return token, expiresAt, nil
+}
+
+// VerifyEmail marks the customer the token was issued to as verified and
+// uses the token up. Tokens that are unknown, expired, issued for an email
+// the customer no longer holds, or belong to a deleted customer return
+// ErrInvalidVerificationToken.
+func (s *Service) VerifyEmail(ctx context.Context, token string) (*database.Customer, error) {
+	if token == "" {
+		return nil, ErrInvalidVerificationToken
+	}
+	var verified *database.Customer
+	err := s.repository.RunInTx(ctx, func(tx Store) error {
+		verification, err := tx.FindEmailVerification(ctx, hashToken(token))
+		if err != nil {
+			return err
+		}
+		if !s.clock.Now().Before(verification.ExpiresAt.Time) {
+			return ErrInvalidVerificationToken
+		}
+		if err := tx.DeleteEmailVerification(ctx, verification.CustomerID); err != nil {
+			return err
+		}
+		verified, err = tx.MarkEmailVerified(ctx, verification.CustomerID, verification.Email)
+		if errors.Is(err, ErrCustomerNotFound) {
+			return ErrInvalidVerificationToken
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("verify email: %w", err)
+	}
+	s.publish(ctx, EventUpdated, verified.ID)
+	return verified, nil
+}
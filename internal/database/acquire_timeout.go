@@ -0,0 +1,207 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrPoolTimeout is returned when no connection became free within the
+// acquire timeout, meaning every connection in the pool is busy
+var ErrPoolTimeout = errors.New("timed out waiting for a database connection")
+
+// TimeoutPool runs queries and transactions on a pool, but gives up waiting
+// for a free connection after acquireTimeout instead of waiting as long as the
+// request context allows. Only the wait for a connection is bounded; a query
+// that is already running is not. A call that fails because its connection
+// was lost before the statement was sent is retried once on another
+// connection. It satisfies the generated DBTX and customer.TxBeginner, so it
+// can stand in for the pool.
+type TimeoutPool struct {
+	pool           *pgxpool.Pool
+	acquireTimeout time.Duration
+}
+
+// NewTimeoutPool wraps pool; a zero acquireTimeout waits for a connection as
+// long as the caller's context allows, as the pool itself does
+func NewTimeoutPool(pool *pgxpool.Pool, acquireTimeout time.Duration) *TimeoutPool {
+	return &TimeoutPool{pool: pool, acquireTimeout: acquireTimeout}
+}
+
+// acquire takes a connection from the pool, reporting ErrPoolTimeout when the
+// acquire timeout, rather than the caller's own context, ran out
+func (p *TimeoutPool) acquire(ctx context.Context) (*pgxpool.Conn, error) {
+	if p.acquireTimeout <= 0 {
+		return p.pool.Acquire(ctx)
+	}
+	acquireCtx, cancel := context.WithTimeout(ctx, p.acquireTimeout)
+	defer cancel()
+	conn, err := p.pool.Acquire(acquireCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(acquireCtx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%w after %s", ErrPoolTimeout, p.acquireTimeout)
+	}
+	return conn, err
+}
+
+func (p *TimeoutPool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	tag, err := p.exec(ctx, sql, args...)
+	if retryable(ctx, err) {
+		return p.exec(ctx, sql, args...)
+	}
+	return tag, err
+}
+
+func (p *TimeoutPool) exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	conn, err := p.acquire(ctx)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	defer conn.Release()
+	return conn.Exec(ctx, sql, args...)
+}
+
+func (p *TimeoutPool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	rows, err := p.query(ctx, sql, args...)
+	if retryable(ctx, err) {
+		return p.query(ctx, sql, args...)
+	}
+	return rows, err
+}
+
+func (p *TimeoutPool) query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	conn, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := conn.Query(ctx, sql, args...)
+	if err != nil {
+		conn.Release()
+		return nil, err
+	}
+	return &releasingRows{Rows: rows, conn: conn}, nil
+}
+
+// QueryRow only learns of an error when the row is scanned, so the retry
+// happens in Scan
+func (p *TimeoutPool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return &retryingRow{pool: p, ctx: ctx, sql: sql, args: args}
+}
+
+func (p *TimeoutPool) queryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	conn, err := p.acquire(ctx)
+	if err != nil {
+		return errRow{err: err}
+	}
+	return &releasingRow{row: conn.QueryRow(ctx, sql, args...), conn: conn}
+}
+
+func (p *TimeoutPool) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := p.begin(ctx)
+	if retryable(ctx, err) {
+		return p.begin(ctx)
+	}
+	return tx, err
+}
+
+func (p *TimeoutPool) begin(ctx context.Context) (pgx.Tx, error) {
+	conn, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		conn.Release()
+		return nil, err
+	}
+	return &releasingTx{Tx: tx, conn: conn}, nil
+}
+
+// releasingRows returns its connection to the pool once the rows are read
+// or closed, as pgxpool does for its own Query
+type releasingRows struct {
+	pgx.Rows
+	conn *pgxpool.Conn
+	once sync.Once
+}
+
+func (r *releasingRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.release()
+	return false
+}
+
+func (r *releasingRows) Close() {
+	r.Rows.Close()
+	r.release()
+}
+
+func (r *releasingRows) release() {
+	r.once.Do(r.conn.Release)
+}
+
+// retryingRow runs its query when scanned, once more if the first attempt
+// lost its connection before the query was sent
+type retryingRow struct {
+	pool *TimeoutPool
+	ctx  context.Context
+	sql  string
+	args []any
+}
+
+func (r *retryingRow) Scan(dest ...any) error {
+	err := r.pool.queryRow(r.ctx, r.sql, r.args...).Scan(dest...)
+	if retryable(r.ctx, err) {
+		return r.pool.queryRow(r.ctx, r.sql, r.args...).Scan(dest...)
+	}
+	return err
+}
+
+// releasingRow returns its connection to the pool after Scan
+type releasingRow struct {
+	row  pgx.Row
+	conn *pgxpool.Conn
+}
+
+func (r *releasingRow) Scan(dest ...any) error {
+	defer r.conn.Release()
+	return r.row.Scan(dest...)
+}
+
+// errRow is the row returned when no connection could be acquired
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(...any) error { return r.err }
+
+// releasingTx returns its connection to the pool once the transaction ends.
+// Rollback after a successful Commit is a no-op, as for any pgx.Tx.
+type releasingTx struct {
+	pgx.Tx
+	conn *pgxpool.Conn
+	once sync.Once
+}
+
+func (tx *releasingTx) Commit(ctx context.Context) error {
+	err := tx.Tx.Commit(ctx)
+	tx.release()
+	return err
+}
+
+func (tx *releasingTx) Rollback(ctx context.Context) error {
+	err := tx.Tx.Rollback(ctx)
+	tx.release()
+	return err
+}
+
+func (tx *releasingTx) release() {
+	tx.once.Do(tx.conn.Release)
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// IsConnectionLost reports whether err means the connection to the database
+// failed, as when the server restarts or the network drops, rather than the
+// statement. Such failures are transient: the same request is expected to
+// succeed on a new connection. Errors from a caller's own context ending are
+// not connection failures.
+func IsConnectionLost(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// Class 08 is connection exceptions; 57P01 to 57P03 are the server
+		// shutting down or not yet accepting connections
+		return strings.HasPrefix(pgErr.Code, "08") || pgErr.Code == "57P01" || pgErr.Code == "57P02" || pgErr.Code == "57P03"
+	}
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	return errors.As(err, &connectErr) ||
+		errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed) ||
+		// pgx reports a connection it already knows to be broken as "conn
+		// closed", an error that is only safe to retry
+		pgconn.SafeToRetry(err)
+}
+
+// retryable reports whether a call that failed with err can be repeated on
+// another connection: the connection was lost before anything reached the
+// server, so the statement cannot have run, and the caller still waits
+func retryable(ctx context.Context, err error) bool {
+	return ctx.Err() == nil && pgconn.SafeToRetry(err) && IsConnectionLost(err)
+}
@@ -2,10 +2,82 @@ package database
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/puddle/v2"
+	"golang.org/x/sync/errgroup"
 )
 
-func NewConnectionPool(ctx context.Context, dbURL string) (*pgxpool.Pool, error) {
-	return pgxpool.New(ctx, dbURL)
+// ErrPoolClosed is returned by queries issued after the pool has been closed,
+// which only happens while the server is shutting down
+var ErrPoolClosed = puddle.ErrClosedPool
+
+// statementCacheModes maps the modes accepted by NewConnectionPool to the
+// pgx query execution mode each one selects
+var statementCacheModes = map[string]pgx.QueryExecMode{
+	// Named prepared statements, cached per connection: the pgx default and
+	// the fastest, but a pooler in transaction mode hands queries to server
+	// connections that never saw the prepare
+	"prepare": pgx.QueryExecModeCacheStatement,
+	// Only parameter and result types are cached, and queries run as unnamed
+	// statements in one round trip, which poolers in transaction mode accept
+	"describe": pgx.QueryExecModeCacheDescribe,
+	// Nothing is cached and parameter types come from the Go values; the
+	// safest choice behind any pooler
+	"disabled": pgx.QueryExecModeExec,
+}
+
+// NewConnectionPool opens a pool for dbURL. A positive minConns overrides the
+// pool_min_conns setting from the URL, a non-empty statementCacheMode, one of
+// prepare, describe or disabled, its default_query_exec_mode, and a positive
+// maxConnLifetimeJitter its pool_max_conn_lifetime_jitter
+func NewConnectionPool(ctx context.Context, dbURL string, minConns int32, statementCacheMode string, maxConnLifetimeJitter time.Duration) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(dbURL)
+	if err != nil {
+		return nil, err
+	}
+	if minConns > 0 {
+		poolConfig.MinConns = minConns
+	}
+	if statementCacheMode != "" {
+		mode, ok := statementCacheModes[statementCacheMode]
+		if !ok {
+			return nil, fmt.Errorf("unknown statement cache mode %q", statementCacheMode)
+		}
+		poolConfig.ConnConfig.DefaultQueryExecMode = mode
+	}
+	if maxConnLifetimeJitter > 0 {
+		poolConfig.MaxConnLifetimeJitter = maxConnLifetimeJitter
+	}
+	return pgxpool.NewWithConfig(ctx, poolConfig)
+}
+
+// WarmPool opens n connections up front by acquiring them all concurrently
+// and then releasing them back to the pool. Holding every connection until
+// the last one is acquired forces the pool to dial n distinct connections
+func WarmPool(ctx context.Context, pool *pgxpool.Pool, n int) error {
+	conns := make([]*pgxpool.Conn, n)
+	defer func() {
+		for _, conn := range conns {
+			if conn != nil {
+				conn.Release()
+			}
+		}
+	}()
+
+	g, ctx := errgroup.WithContext(ctx)
+	for i := range conns {
+		g.Go(func() error {
+			conn, err := pool.Acquire(ctx)
+			if err != nil {
+				return fmt.Errorf("warm pool: %w", err)
+			}
+			conns[i] = conn
+			return nil
+		})
+	}
+	return g.Wait()
 }
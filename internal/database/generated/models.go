@@ -9,10 +9,36 @@ import (
 )
 
 type Customer struct {
-	ID        int32
-	Name      string
-	Email     string
-	Password  string
-	CreatedAt pgtype.Timestamp
-	UpdatedAt pgtype.Timestamp
+	ID            int32
+	Name          string
+	Email         string
+	Password      string
+	CreatedAt     pgtype.Timestamp
+	UpdatedAt     pgtype.Timestamp
+	IsActive      bool
+	DeletedAt     pgtype.Timestamp
+	Phone         pgtype.Text
+	AvatarUrl     pgtype.Text
+	LastLoginAt   pgtype.Timestamp
+	EmailVerified bool
+}
+
+type CustomerApiToken struct {
+	CustomerID int32
+	TokenHash  string
+	CreatedAt  pgtype.Timestamp
+}
+
+type CustomerEmailVerification struct {
+	CustomerID int32
+	TokenHash  string
+	ExpiresAt  pgtype.Timestamp
+	CreatedAt  pgtype.Timestamp
+	Email      string
+}
+
+type CustomerTag struct {
+	CustomerID int32
+	Tag        string
+	CreatedAt  pgtype.Timestamp
 }
@@ -7,32 +7,210 @@ package database
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addCustomerTag = `-- name: AddCustomerTag :exec
+INSERT INTO customer_tags (customer_id, tag)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING
+`
+
+type AddCustomerTagParams struct {
+	CustomerID int32
+	Tag        string
+}
+
+func (q *Queries) AddCustomerTag(ctx context.Context, arg AddCustomerTagParams) error {
+	_, err := q.db.Exec(ctx, addCustomerTag, arg.CustomerID, arg.Tag)
+	return err
+}
+
+const anonymizeCustomer = `-- name: AnonymizeCustomer :one
+UPDATE customers
+SET
+    name = $2,
+    email = $3,
+    password = $4,
+    phone = NULL,
+    avatar_url = NULL,
+    last_login_at = NULL,
+    email_verified = FALSE,
+    is_active = FALSE,
+    deleted_at = COALESCE(deleted_at, NOW()),
+    updated_at = NOW()
+WHERE id = $1
+RETURNING
+    id,
+    name,
+    email,
+    password,
+    created_at,
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified
+`
+
+type AnonymizeCustomerParams struct {
+	ID       int32
+	Name     string
+	Email    string
+	Password string
+}
+
+func (q *Queries) AnonymizeCustomer(ctx context.Context, arg AnonymizeCustomerParams) (Customer, error) {
+	row := q.db.QueryRow(ctx, anonymizeCustomer,
+		arg.ID,
+		arg.Name,
+		arg.Email,
+		arg.Password,
+	)
+	var i Customer
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsActive,
+		&i.DeletedAt,
+		&i.Phone,
+		&i.AvatarUrl,
+		&i.LastLoginAt,
+		&i.EmailVerified,
+	)
+	return i, err
+}
+
+const countCustomers = `-- name: CountCustomers :one
+SELECT COUNT(*)
+FROM customers
+WHERE deleted_at IS NULL
+  AND ($1::boolean IS NULL OR is_active = $1)
+  AND ($2::text IS NULL OR EXISTS (
+      SELECT 1 FROM customer_tags
+      WHERE customer_tags.customer_id = customers.id AND customer_tags.tag = $2
+  ))
+`
+
+type CountCustomersParams struct {
+	IsActive pgtype.Bool
+	Tag      pgtype.Text
+}
+
+func (q *Queries) CountCustomers(ctx context.Context, arg CountCustomersParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countCustomers, arg.IsActive, arg.Tag)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSearchCustomers = `-- name: CountSearchCustomers :one
+SELECT COUNT(*)
+FROM customers
+WHERE deleted_at IS NULL
+  AND (name ILIKE $1 OR email ILIKE $1)
+`
+
+func (q *Queries) CountSearchCustomers(ctx context.Context, pattern string) (int64, error) {
+	row := q.db.QueryRow(ctx, countSearchCustomers, pattern)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSignupsByDay = `-- name: CountSignupsByDay :many
+-- Counts every customer created in [since, until), deleted ones included,
+-- since a later deletion does not undo the signup. Days without signups
+-- are absent.
+SELECT
+    date_trunc('day', created_at)::date AS day,
+    COUNT(*) AS signups
+FROM customers
+WHERE created_at >= $1 AND created_at < $2
+GROUP BY day
+ORDER BY day
+`
+
+type CountSignupsByDayParams struct {
+	Since pgtype.Timestamp
+	Until pgtype.Timestamp
+}
+
+type CountSignupsByDayRow struct {
+	Day     pgtype.Date
+	Signups int64
+}
+
+// Counts every customer created in [since, until), deleted ones included,
+// since a later deletion does not undo the signup. Days without signups
+// are absent.
+func (q *Queries) CountSignupsByDay(ctx context.Context, arg CountSignupsByDayParams) ([]CountSignupsByDayRow, error) {
+	rows, err := q.db.Query(ctx, countSignupsByDay, arg.Since, arg.Until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountSignupsByDayRow
+	for rows.Next() {
+		var i CountSignupsByDayRow
+		if err := rows.Scan(&i.Day, &i.Signups); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createCustomer = `-- name: CreateCustomer :one
 INSERT INTO customers (
     name,
     email,
-    password
+    password,
+    phone,
+    avatar_url
 )
-VALUES ($1, $2, $3)
+VALUES ($1, $2, $3, $4, $5)
 RETURNING
     id,
     name,
     email,
     password,
     created_at,
-    updated_at
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified
 `
 
 type CreateCustomerParams struct {
-	Name     string
-	Email    string
-	Password string
+	Name      string
+	Email     string
+	Password  string
+	Phone     pgtype.Text
+	AvatarUrl pgtype.Text
 }
 
 func (q *Queries) CreateCustomer(ctx context.Context, arg CreateCustomerParams) (Customer, error) {
-	row := q.db.QueryRow(ctx, createCustomer, arg.Name, arg.Email, arg.Password)
+	row := q.db.QueryRow(ctx, createCustomer,
+		arg.Name,
+		arg.Email,
+		arg.Password,
+		arg.Phone,
+		arg.AvatarUrl,
+	)
 	var i Customer
 	err := row.Scan(
 		&i.ID,
@@ -41,21 +219,74 @@ func (q *Queries) CreateCustomer(ctx context.Context, arg CreateCustomerParams)
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IsActive,
+		&i.DeletedAt,
+		&i.Phone,
+		&i.AvatarUrl,
+		&i.LastLoginAt,
+		&i.EmailVerified,
 	)
 	return i, err
 }
 
-const deleteCustomerByEmail = `-- name: DeleteCustomerByEmail :execrows
+const deleteCustomerAPIToken = `-- name: DeleteCustomerAPIToken :exec
+DELETE FROM customer_api_tokens
+WHERE customer_id = $1
+`
+
+func (q *Queries) DeleteCustomerAPIToken(ctx context.Context, customerID int32) error {
+	_, err := q.db.Exec(ctx, deleteCustomerAPIToken, customerID)
+	return err
+}
+
+const deleteCustomerByEmail = `-- name: DeleteCustomerByEmail :many
 DELETE FROM customers
-WHERE email = $1
+WHERE LOWER(email) = LOWER($1)
+RETURNING id
 `
 
-func (q *Queries) DeleteCustomerByEmail(ctx context.Context, email string) (int64, error) {
-	result, err := q.db.Exec(ctx, deleteCustomerByEmail, email)
+func (q *Queries) DeleteCustomerByEmail(ctx context.Context, email string) ([]int32, error) {
+	rows, err := q.db.Query(ctx, deleteCustomerByEmail, email)
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
-	return result.RowsAffected(), nil
+	return items, nil
+}
+
+const deleteEmailVerification = `-- name: DeleteEmailVerification :exec
+DELETE FROM customer_email_verifications
+WHERE customer_id = $1
+`
+
+func (q *Queries) DeleteEmailVerification(ctx context.Context, customerID int32) error {
+	_, err := q.db.Exec(ctx, deleteEmailVerification, customerID)
+	return err
+}
+
+const existsCustomerByID = `-- name: ExistsCustomerByID :one
+SELECT EXISTS (
+    SELECT 1 FROM customers
+    WHERE id = $1 AND deleted_at IS NULL
+)
+`
+
+func (q *Queries) ExistsCustomerByID(ctx context.Context, id int32) (bool, error) {
+	row := q.db.QueryRow(ctx, existsCustomerByID, id)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
 }
 
 const getCustomerByEmail = `-- name: GetCustomerByEmail :one
@@ -65,9 +296,15 @@ SELECT
     email,
     password,
     created_at,
-    updated_at
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified
 FROM customers
-WHERE email = $1
+WHERE LOWER(email) = LOWER($1) AND deleted_at IS NULL
 LIMIT 1
 `
 
@@ -81,6 +318,12 @@ func (q *Queries) GetCustomerByEmail(ctx context.Context, email string) (Custome
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IsActive,
+		&i.DeletedAt,
+		&i.Phone,
+		&i.AvatarUrl,
+		&i.LastLoginAt,
+		&i.EmailVerified,
 	)
 	return i, err
 }
@@ -92,9 +335,15 @@ SELECT
     email,
     password,
     created_at,
-    updated_at
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified
 FROM customers
-WHERE id = $1
+WHERE id = $1 AND deleted_at IS NULL
 LIMIT 1
 `
 
@@ -108,10 +357,156 @@ func (q *Queries) GetCustomerByID(ctx context.Context, id int32) (Customer, erro
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IsActive,
+		&i.DeletedAt,
+		&i.Phone,
+		&i.AvatarUrl,
+		&i.LastLoginAt,
+		&i.EmailVerified,
 	)
 	return i, err
 }
 
+const getCustomerByIDForUpdate = `-- name: GetCustomerByIDForUpdate :one
+SELECT
+    id,
+    name,
+    email,
+    password,
+    created_at,
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified
+FROM customers
+WHERE id = $1 AND deleted_at IS NULL
+LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetCustomerByIDForUpdate(ctx context.Context, id int32) (Customer, error) {
+	row := q.db.QueryRow(ctx, getCustomerByIDForUpdate, id)
+	var i Customer
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsActive,
+		&i.DeletedAt,
+		&i.Phone,
+		&i.AvatarUrl,
+		&i.LastLoginAt,
+		&i.EmailVerified,
+	)
+	return i, err
+}
+
+const getCustomerIDByAPITokenHash = `-- name: GetCustomerIDByAPITokenHash :one
+SELECT t.customer_id, c.is_active
+FROM customer_api_tokens t
+JOIN customers c ON c.id = t.customer_id
+WHERE t.token_hash = $1 AND c.deleted_at IS NULL
+`
+
+type GetCustomerIDByAPITokenHashRow struct {
+	CustomerID int32
+	IsActive   bool
+}
+
+func (q *Queries) GetCustomerIDByAPITokenHash(ctx context.Context, tokenHash string) (GetCustomerIDByAPITokenHashRow, error) {
+	row := q.db.QueryRow(ctx, getCustomerIDByAPITokenHash, tokenHash)
+	var i GetCustomerIDByAPITokenHashRow
+	err := row.Scan(&i.CustomerID, &i.IsActive)
+	return i, err
+}
+
+const getCustomerStats = `-- name: GetCustomerStats :one
+SELECT
+    COUNT(*) AS total,
+    COUNT(*) FILTER (WHERE created_at >= NOW() - INTERVAL '24 hours') AS created_last_24h,
+    COUNT(*) FILTER (WHERE created_at >= NOW() - INTERVAL '7 days') AS created_last_7d,
+    COUNT(*) FILTER (WHERE created_at >= NOW() - INTERVAL '30 days') AS created_last_30d,
+    COUNT(*) FILTER (WHERE is_active) AS active,
+    COUNT(*) FILTER (WHERE NOT is_active) AS inactive
+FROM customers
+WHERE deleted_at IS NULL
+`
+
+type GetCustomerStatsRow struct {
+	Total          int64
+	CreatedLast24h int64
+	CreatedLast7d  int64
+	CreatedLast30d int64
+	Active         int64
+	Inactive       int64
+}
+
+func (q *Queries) GetCustomerStats(ctx context.Context) (GetCustomerStatsRow, error) {
+	row := q.db.QueryRow(ctx, getCustomerStats)
+	var i GetCustomerStatsRow
+	err := row.Scan(
+		&i.Total,
+		&i.CreatedLast24h,
+		&i.CreatedLast7d,
+		&i.CreatedLast30d,
+		&i.Active,
+		&i.Inactive,
+	)
+	return i, err
+}
+
+const getEmailVerificationByTokenHash = `-- name: GetEmailVerificationByTokenHash :one
+SELECT customer_id, token_hash, expires_at, created_at, email
+FROM customer_email_verifications
+WHERE token_hash = $1
+`
+
+func (q *Queries) GetEmailVerificationByTokenHash(ctx context.Context, tokenHash string) (CustomerEmailVerification, error) {
+	row := q.db.QueryRow(ctx, getEmailVerificationByTokenHash, tokenHash)
+	var i CustomerEmailVerification
+	err := row.Scan(
+		&i.CustomerID,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.Email,
+	)
+	return i, err
+}
+
+const listCustomerTags = `-- name: ListCustomerTags :many
+SELECT tag
+FROM customer_tags
+WHERE customer_id = $1
+ORDER BY tag
+`
+
+func (q *Queries) ListCustomerTags(ctx context.Context, customerID int32) ([]string, error) {
+	rows, err := q.db.Query(ctx, listCustomerTags, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		items = append(items, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listCustomers = `-- name: ListCustomers :many
 SELECT
     id,
@@ -119,13 +514,101 @@ SELECT
     email,
     password,
     created_at,
-    updated_at
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified
 FROM customers
+WHERE deleted_at IS NULL
+  AND ($1::boolean IS NULL OR is_active = $1)
+  AND ($2::text IS NULL OR EXISTS (
+      SELECT 1 FROM customer_tags
+      WHERE customer_tags.customer_id = customers.id AND customer_tags.tag = $2
+  ))
+ORDER BY
+    CASE WHEN $3::text = 'name' THEN name END,
+    CASE WHEN $3::text = 'created_at' THEN created_at END,
+    id
+LIMIT $4 OFFSET $5
+`
+
+type ListCustomersParams struct {
+	IsActive pgtype.Bool
+	Tag      pgtype.Text
+	Sort     string
+	Limit    int32
+	Offset   int32
+}
+
+func (q *Queries) ListCustomers(ctx context.Context, arg ListCustomersParams) ([]Customer, error) {
+	rows, err := q.db.Query(ctx, listCustomers,
+		arg.IsActive,
+		arg.Tag,
+		arg.Sort,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Customer
+	for rows.Next() {
+		var i Customer
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.Password,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.IsActive,
+			&i.DeletedAt,
+			&i.Phone,
+			&i.AvatarUrl,
+			&i.LastLoginAt,
+			&i.EmailVerified,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCustomersAfterID = `-- name: ListCustomersAfterID :many
+SELECT
+    id,
+    name,
+    email,
+    password,
+    created_at,
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified
+FROM customers
+WHERE deleted_at IS NULL AND id > $1
 ORDER BY id
+LIMIT $2
 `
 
-func (q *Queries) ListCustomers(ctx context.Context) ([]Customer, error) {
-	rows, err := q.db.Query(ctx, listCustomers)
+type ListCustomersAfterIDParams struct {
+	ID    int32
+	Limit int32
+}
+
+func (q *Queries) ListCustomersAfterID(ctx context.Context, arg ListCustomersAfterIDParams) ([]Customer, error) {
+	rows, err := q.db.Query(ctx, listCustomersAfterID, arg.ID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -140,6 +623,12 @@ func (q *Queries) ListCustomers(ctx context.Context) ([]Customer, error) {
 			&i.Password,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.IsActive,
+			&i.DeletedAt,
+			&i.Phone,
+			&i.AvatarUrl,
+			&i.LastLoginAt,
+			&i.EmailVerified,
 		); err != nil {
 			return nil, err
 		}
@@ -151,6 +640,460 @@ func (q *Queries) ListCustomers(ctx context.Context) ([]Customer, error) {
 	return items, nil
 }
 
+const listCustomersByEmails = `-- name: ListCustomersByEmails :many
+SELECT
+    id,
+    name,
+    email,
+    password,
+    created_at,
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified
+FROM customers
+WHERE deleted_at IS NULL AND LOWER(email) = ANY($1::text[])
+ORDER BY id
+`
+
+func (q *Queries) ListCustomersByEmails(ctx context.Context, emails []string) ([]Customer, error) {
+	rows, err := q.db.Query(ctx, listCustomersByEmails, emails)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Customer
+	for rows.Next() {
+		var i Customer
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.Password,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.IsActive,
+			&i.DeletedAt,
+			&i.Phone,
+			&i.AvatarUrl,
+			&i.LastLoginAt,
+			&i.EmailVerified,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markEmailVerified = `-- name: MarkEmailVerified :one
+UPDATE customers
+SET
+    email_verified = TRUE,
+    updated_at = NOW()
+WHERE id = $1 AND LOWER(email) = LOWER($2) AND deleted_at IS NULL
+RETURNING
+    id,
+    name,
+    email,
+    password,
+    created_at,
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified
+`
+
+type MarkEmailVerifiedParams struct {
+	ID    int32
+	Email string
+}
+
+// Only marks the customer while they still hold the email the token was
+// issued for; after a change of email no row is returned.
+func (q *Queries) MarkEmailVerified(ctx context.Context, arg MarkEmailVerifiedParams) (Customer, error) {
+	row := q.db.QueryRow(ctx, markEmailVerified, arg.ID, arg.Email)
+	var i Customer
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsActive,
+		&i.DeletedAt,
+		&i.Phone,
+		&i.AvatarUrl,
+		&i.LastLoginAt,
+		&i.EmailVerified,
+	)
+	return i, err
+}
+
+const moveCustomerTags = `-- name: MoveCustomerTags :exec
+WITH moved AS (
+    DELETE FROM customer_tags
+    WHERE customer_id = $1
+    RETURNING tag
+)
+INSERT INTO customer_tags (customer_id, tag)
+SELECT $2::int, tag FROM moved
+ON CONFLICT DO NOTHING
+`
+
+type MoveCustomerTagsParams struct {
+	FromID int32
+	ToID   int32
+}
+
+func (q *Queries) MoveCustomerTags(ctx context.Context, arg MoveCustomerTagsParams) error {
+	_, err := q.db.Exec(ctx, moveCustomerTags, arg.FromID, arg.ToID)
+	return err
+}
+
+const patchCustomer = `-- name: PatchCustomer :one
+UPDATE customers
+SET
+    name = COALESCE($1, name),
+    email = COALESCE($2, email),
+    password = COALESCE($3, password),
+    phone = CASE WHEN $4::text = '' THEN NULL ELSE COALESCE($4, phone) END,
+    avatar_url = CASE WHEN $5::text = '' THEN NULL ELSE COALESCE($5, avatar_url) END,
+    email_verified = email_verified AND ($2::varchar IS NULL OR LOWER(email) = LOWER($2)),
+    updated_at = NOW()
+WHERE id = $6 AND deleted_at IS NULL
+  AND ($7::timestamp IS NULL OR updated_at = $7)
+  AND (name, email, password, phone, avatar_url) IS DISTINCT FROM (
+      COALESCE($1, name),
+      COALESCE($2, email),
+      COALESCE($3, password),
+      CASE WHEN $4::text = '' THEN NULL ELSE COALESCE($4, phone) END,
+      CASE WHEN $5::text = '' THEN NULL ELSE COALESCE($5, avatar_url) END
+  )
+RETURNING
+    id,
+    name,
+    email,
+    password,
+    created_at,
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified
+`
+
+type PatchCustomerParams struct {
+	Name              pgtype.Text
+	Email             pgtype.Text
+	Password          pgtype.Text
+	Phone             pgtype.Text
+	AvatarUrl         pgtype.Text
+	ID                int32
+	ExpectedUpdatedAt pgtype.Timestamp
+}
+
+func (q *Queries) PatchCustomer(ctx context.Context, arg PatchCustomerParams) (Customer, error) {
+	row := q.db.QueryRow(ctx, patchCustomer,
+		arg.Name,
+		arg.Email,
+		arg.Password,
+		arg.Phone,
+		arg.AvatarUrl,
+		arg.ID,
+		arg.ExpectedUpdatedAt,
+	)
+	var i Customer
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsActive,
+		&i.DeletedAt,
+		&i.Phone,
+		&i.AvatarUrl,
+		&i.LastLoginAt,
+		&i.EmailVerified,
+	)
+	return i, err
+}
+
+const removeCustomerTag = `-- name: RemoveCustomerTag :exec
+DELETE FROM customer_tags
+WHERE customer_id = $1 AND tag = $2
+`
+
+type RemoveCustomerTagParams struct {
+	CustomerID int32
+	Tag        string
+}
+
+func (q *Queries) RemoveCustomerTag(ctx context.Context, arg RemoveCustomerTagParams) error {
+	_, err := q.db.Exec(ctx, removeCustomerTag, arg.CustomerID, arg.Tag)
+	return err
+}
+
+const searchCustomers = `-- name: SearchCustomers :many
+SELECT
+    id,
+    name,
+    email,
+    password,
+    created_at,
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified
+FROM customers
+WHERE deleted_at IS NULL
+  AND (name ILIKE $1 OR email ILIKE $1)
+ORDER BY
+    LOWER(email) = LOWER($2::text) DESC,
+    id
+LIMIT $3 OFFSET $4
+`
+
+type SearchCustomersParams struct {
+	Pattern string
+	Query   string
+	Limit   int32
+	Offset  int32
+}
+
+// Matches the pattern against name or email; a customer whose email equals
+// the query exactly ranks first.
+func (q *Queries) SearchCustomers(ctx context.Context, arg SearchCustomersParams) ([]Customer, error) {
+	rows, err := q.db.Query(ctx, searchCustomers,
+		arg.Pattern,
+		arg.Query,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Customer
+	for rows.Next() {
+		var i Customer
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.Password,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.IsActive,
+			&i.DeletedAt,
+			&i.Phone,
+			&i.AvatarUrl,
+			&i.LastLoginAt,
+			&i.EmailVerified,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setCustomerAPIToken = `-- name: SetCustomerAPIToken :exec
+INSERT INTO customer_api_tokens (customer_id, token_hash)
+VALUES ($1, $2)
+ON CONFLICT (customer_id) DO UPDATE
+SET token_hash = EXCLUDED.token_hash, created_at = NOW()
+`
+
+type SetCustomerAPITokenParams struct {
+	CustomerID int32
+	TokenHash  string
+}
+
+func (q *Queries) SetCustomerAPIToken(ctx context.Context, arg SetCustomerAPITokenParams) error {
+	_, err := q.db.Exec(ctx, setCustomerAPIToken, arg.CustomerID, arg.TokenHash)
+	return err
+}
+
+const setCustomerActive = `-- name: SetCustomerActive :one
+UPDATE customers
+SET
+    is_active = $2,
+    updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING
+    id,
+    name,
+    email,
+    password,
+    created_at,
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified
+`
+
+type SetCustomerActiveParams struct {
+	ID       int32
+	IsActive bool
+}
+
+func (q *Queries) SetCustomerActive(ctx context.Context, arg SetCustomerActiveParams) (Customer, error) {
+	row := q.db.QueryRow(ctx, setCustomerActive, arg.ID, arg.IsActive)
+	var i Customer
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsActive,
+		&i.DeletedAt,
+		&i.Phone,
+		&i.AvatarUrl,
+		&i.LastLoginAt,
+		&i.EmailVerified,
+	)
+	return i, err
+}
+
+const setCustomersActive = `-- name: SetCustomersActive :many
+UPDATE customers
+SET
+    is_active = $1,
+    updated_at = NOW()
+WHERE id = ANY($2::int[]) AND deleted_at IS NULL
+RETURNING id
+`
+
+type SetCustomersActiveParams struct {
+	IsActive bool
+	Ids      []int32
+}
+
+func (q *Queries) SetCustomersActive(ctx context.Context, arg SetCustomersActiveParams) ([]int32, error) {
+	rows, err := q.db.Query(ctx, setCustomersActive, arg.IsActive, arg.Ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setEmailVerification = `-- name: SetEmailVerification :exec
+INSERT INTO customer_email_verifications (customer_id, token_hash, expires_at, email)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (customer_id) DO UPDATE
+SET token_hash = EXCLUDED.token_hash, expires_at = EXCLUDED.expires_at, email = EXCLUDED.email, created_at = NOW()
+`
+
+type SetEmailVerificationParams struct {
+	CustomerID int32
+	TokenHash  string
+	ExpiresAt  pgtype.Timestamp
+	Email      string
+}
+
+// Replaces any pending verification, so only the newest token works.
+func (q *Queries) SetEmailVerification(ctx context.Context, arg SetEmailVerificationParams) error {
+	_, err := q.db.Exec(ctx, setEmailVerification, arg.CustomerID, arg.TokenHash, arg.ExpiresAt, arg.Email)
+	return err
+}
+
+const softDeleteCustomer = `-- name: SoftDeleteCustomer :one
+UPDATE customers
+SET
+    deleted_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING
+    id,
+    name,
+    email,
+    password,
+    created_at,
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified
+`
+
+func (q *Queries) SoftDeleteCustomer(ctx context.Context, id int32) (Customer, error) {
+	row := q.db.QueryRow(ctx, softDeleteCustomer, id)
+	var i Customer
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsActive,
+		&i.DeletedAt,
+		&i.Phone,
+		&i.AvatarUrl,
+		&i.LastLoginAt,
+		&i.EmailVerified,
+	)
+	return i, err
+}
+
+const touchCustomer = `-- name: TouchCustomer :one
+-- Bumps updated_at alone, so caches keyed on it, ETags included, see a new
+-- version.
+UPDATE customers
+SET updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING updated_at
+`
+
+// Bumps updated_at alone, so caches keyed on it, ETags included, see a new
+// version.
+func (q *Queries) TouchCustomer(ctx context.Context, id int32) (pgtype.Timestamp, error) {
+	row := q.db.QueryRow(ctx, touchCustomer, id)
+	var updated_at pgtype.Timestamp
+	err := row.Scan(&updated_at)
+	return updated_at, err
+}
+
 const updateCustomer = `-- name: UpdateCustomer :one
 UPDATE customers
 SET
@@ -158,15 +1101,22 @@ SET
     name = $2,
     email = $3,
     password = $4,
+    email_verified = email_verified AND LOWER(email) = LOWER($3),
     updated_at = NOW()
-WHERE id = $1
+WHERE id = $1 AND deleted_at IS NULL
 RETURNING
     id,
     name,
     email,
     password,
     created_at,
-    updated_at
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified
 `
 
 type UpdateCustomerParams struct {
@@ -191,6 +1141,163 @@ func (q *Queries) UpdateCustomer(ctx context.Context, arg UpdateCustomerParams)
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IsActive,
+		&i.DeletedAt,
+		&i.Phone,
+		&i.AvatarUrl,
+		&i.LastLoginAt,
+		&i.EmailVerified,
+	)
+	return i, err
+}
+
+const updateCustomerName = `-- name: UpdateCustomerName :one
+UPDATE customers
+SET
+    name = $2,
+    updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING
+    id,
+    name,
+    email,
+    password,
+    created_at,
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified
+`
+
+type UpdateCustomerNameParams struct {
+	ID   int32
+	Name string
+}
+
+func (q *Queries) UpdateCustomerName(ctx context.Context, arg UpdateCustomerNameParams) (Customer, error) {
+	row := q.db.QueryRow(ctx, updateCustomerName, arg.ID, arg.Name)
+	var i Customer
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsActive,
+		&i.DeletedAt,
+		&i.Phone,
+		&i.AvatarUrl,
+		&i.LastLoginAt,
+		&i.EmailVerified,
+	)
+	return i, err
+}
+
+const updateLastLogin = `-- name: UpdateLastLogin :exec
+UPDATE customers
+SET last_login_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+// Leaves updated_at alone: a login is not a change to the customer, and
+// bumping it would invalidate every ETag a client holds.
+func (q *Queries) UpdateLastLogin(ctx context.Context, id int32) error {
+	_, err := q.db.Exec(ctx, updateLastLogin, id)
+	return err
+}
+
+const upsertCustomerByEmail = `-- name: UpsertCustomerByEmail :one
+-- Creates the customer, or overwrites the live customer holding the email in
+-- any case.
+-- A soft-deleted holder is left alone and no row is returned. inserted
+-- tells the two outcomes apart: xmax is only zero for a freshly inserted row.
+INSERT INTO customers (
+    name,
+    email,
+    password,
+    phone,
+    avatar_url
+)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT ((LOWER(email))) DO UPDATE
+SET
+    name = EXCLUDED.name,
+    password = EXCLUDED.password,
+    phone = EXCLUDED.phone,
+    avatar_url = EXCLUDED.avatar_url,
+    updated_at = NOW()
+WHERE customers.deleted_at IS NULL
+RETURNING
+    id,
+    name,
+    email,
+    password,
+    created_at,
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified,
+    (xmax = 0) AS inserted
+`
+
+type UpsertCustomerByEmailParams struct {
+	Name      string
+	Email     string
+	Password  string
+	Phone     pgtype.Text
+	AvatarUrl pgtype.Text
+}
+
+type UpsertCustomerByEmailRow struct {
+	ID            int32
+	Name          string
+	Email         string
+	Password      string
+	CreatedAt     pgtype.Timestamp
+	UpdatedAt     pgtype.Timestamp
+	IsActive      bool
+	DeletedAt     pgtype.Timestamp
+	Phone         pgtype.Text
+	AvatarUrl     pgtype.Text
+	LastLoginAt   pgtype.Timestamp
+	EmailVerified bool
+	Inserted      bool
+}
+
+// Creates the customer, or overwrites the live customer holding the email in
+// any case.
+// A soft-deleted holder is left alone and no row is returned. inserted
+// tells the two outcomes apart: xmax is only zero for a freshly inserted row.
+func (q *Queries) UpsertCustomerByEmail(ctx context.Context, arg UpsertCustomerByEmailParams) (UpsertCustomerByEmailRow, error) {
+	row := q.db.QueryRow(ctx, upsertCustomerByEmail,
+		arg.Name,
+		arg.Email,
+		arg.Password,
+		arg.Phone,
+		arg.AvatarUrl,
+	)
+	var i UpsertCustomerByEmailRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsActive,
+		&i.DeletedAt,
+		&i.Phone,
+		&i.AvatarUrl,
+		&i.LastLoginAt,
+		&i.EmailVerified,
+		&i.Inserted,
 	)
 	return i, err
 }
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// EmailConflict is a set of customers whose emails differ only in case, so
+// lowercasing them would break the unique constraint on email
+type EmailConflict struct {
+	Email string
+	IDs   []int32
+}
+
+// LowercaseEmails stores every customer email in lower case, the form new
+// and updated emails are saved in. Lookups compare emails case-insensitively
+// either way, so this only tidies rows saved before emails were lowercased.
+// Rows whose lowercased email another row already holds, in any case, are
+// left untouched and reported as conflicts: they block the unique index of
+// migration 0010 and must be merged or fixed by hand. Soft-deleted rows
+// count, as they still hold their email. Running it again only repeats the
+// report.
+func LowercaseEmails(ctx context.Context, pool *pgxpool.Pool) (normalized int64, conflicts []EmailConflict, err error) {
+	tag, err := pool.Exec(ctx, `
+		UPDATE customers c
+		SET email = LOWER(c.email), updated_at = NOW()
+		WHERE c.email <> LOWER(c.email)
+		  AND NOT EXISTS (
+			SELECT 1 FROM customers other
+			WHERE other.id <> c.id AND LOWER(other.email) = LOWER(c.email)
+		  )`)
+	if err != nil {
+		return 0, nil, fmt.Errorf("lowercase emails: %w", err)
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT LOWER(email), array_agg(id ORDER BY id)
+		FROM customers
+		GROUP BY LOWER(email)
+		HAVING COUNT(*) > 1
+		ORDER BY LOWER(email)`)
+	if err != nil {
+		return 0, nil, fmt.Errorf("find email conflicts: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var conflict EmailConflict
+		if err := rows.Scan(&conflict.Email, &conflict.IDs); err != nil {
+			return 0, nil, fmt.Errorf("find email conflicts: %w", err)
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, fmt.Errorf("find email conflicts: %w", err)
+	}
+	return tag.RowsAffected(), conflicts, nil
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// expectedCustomerColumns mirrors schema.sql, which the sqlc generated code is built from
+var expectedCustomerColumns = []struct {
+	name     string
+	dataType string
+}{
+	{"id", "integer"},
+	{"name", "character varying"},
+	{"email", "character varying"},
+	{"password", "character varying"},
+	{"created_at", "timestamp without time zone"},
+	{"updated_at", "timestamp without time zone"},
+	{"is_active", "boolean"},
+	{"deleted_at", "timestamp without time zone"},
+	{"phone", "character varying"},
+	{"avatar_url", "character varying"},
+	{"last_login_at", "timestamp without time zone"},
+	{"email_verified", "boolean"},
+}
+
+// CheckSchema compares the live customers table with the columns the generated
+// queries expect and returns an error listing every difference
+func CheckSchema(ctx context.Context, pool *pgxpool.Pool) error {
+	rows, err := pool.Query(ctx, `
+		SELECT column_name, data_type
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'customers'`)
+	if err != nil {
+		return fmt.Errorf("read customers columns: %w", err)
+	}
+	defer rows.Close()
+
+	actual := make(map[string]string)
+	for rows.Next() {
+		var name, dataType string
+		if err := rows.Scan(&name, &dataType); err != nil {
+			return fmt.Errorf("read customers columns: %w", err)
+		}
+		actual[name] = dataType
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("read customers columns: %w", err)
+	}
+
+	var diff []string
+	for _, col := range expectedCustomerColumns {
+		got, ok := actual[col.name]
+		switch {
+		case !ok:
+			diff = append(diff, fmt.Sprintf("- %s %s (missing)", col.name, col.dataType))
+		case got != col.dataType:
+			diff = append(diff, fmt.Sprintf("~ %s: expected %s, got %s", col.name, col.dataType, got))
+		}
+	}
+	if len(diff) > 0 {
+		return fmt.Errorf("customers table does not match the generated code:\n%s", strings.Join(diff, "\n"))
+	}
+	return nil
+}
+
+// CheckUniqueNameIndex verifies the optional unique name index from migration
+// 0003 exists, as REQUIRE_UNIQUE_NAME relies on it
+func CheckUniqueNameIndex(ctx context.Context, pool *pgxpool.Pool) error {
+	var exists bool
+	err := pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM pg_indexes
+			WHERE schemaname = current_schema() AND indexname = 'customers_name_key'
+		)`).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("read customers indexes: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("REQUIRE_UNIQUE_NAME is enabled but index customers_name_key is missing: apply migration 0003")
+	}
+	return nil
+}
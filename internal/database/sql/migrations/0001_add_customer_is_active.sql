@@ -0,0 +1,3 @@
+-- Customers can be suspended without being deleted.
+ALTER TABLE customers
+    ADD COLUMN is_active BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,3 @@
+-- Soft-deleted customers keep their row with deleted_at set.
+ALTER TABLE customers
+    ADD COLUMN deleted_at TIMESTAMP;
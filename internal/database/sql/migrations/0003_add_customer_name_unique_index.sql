@@ -0,0 +1,6 @@
+-- Optional: only apply when REQUIRE_UNIQUE_NAME is enabled.
+-- Existing duplicate names must be cleaned up first or this will fail; find them with
+--   SELECT name, COUNT(*) FROM customers WHERE deleted_at IS NULL GROUP BY name HAVING COUNT(*) > 1;
+CREATE UNIQUE INDEX customers_name_key
+    ON customers (name)
+    WHERE deleted_at IS NULL;
@@ -0,0 +1,3 @@
+-- Optional contact number in E.164 format, e.g. +14155552671.
+ALTER TABLE customers
+    ADD COLUMN phone VARCHAR;
@@ -0,0 +1,10 @@
+-- Free-form labels such as "vip" or "trial" used to segment customers.
+CREATE TABLE customer_tags (
+    customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    tag VARCHAR NOT NULL,
+    created_at TIMESTAMP DEFAULT now(),
+    PRIMARY KEY (customer_id, tag)
+);
+
+-- Serves the ?tag= filter on the customer list.
+CREATE INDEX customer_tags_tag_idx ON customer_tags (tag);
@@ -0,0 +1,3 @@
+-- Optional http(s) link to the customer's profile picture.
+ALTER TABLE customers
+    ADD COLUMN avatar_url VARCHAR;
@@ -0,0 +1,7 @@
+-- One API token per customer. Only a SHA-256 hash of the token is stored;
+-- the plaintext is returned once, when the token is issued.
+CREATE TABLE customer_api_tokens (
+    customer_id INTEGER PRIMARY KEY REFERENCES customers(id) ON DELETE CASCADE,
+    token_hash VARCHAR NOT NULL,
+    created_at TIMESTAMP DEFAULT now()
+);
@@ -0,0 +1,3 @@
+-- Set on every successful login; NULL for customers who never logged in.
+ALTER TABLE customers
+    ADD COLUMN last_login_at TIMESTAMP;
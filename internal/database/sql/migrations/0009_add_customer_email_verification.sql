@@ -0,0 +1,14 @@
+-- Customers created before verification existed are treated as verified, so
+-- turning on REQUIRE_VERIFIED_EMAIL does not lock them out; new customers
+-- start unverified.
+ALTER TABLE customers ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE customers ALTER COLUMN email_verified SET DEFAULT FALSE;
+
+-- At most one pending verification per customer. Only a SHA-256 hash of the
+-- token is stored; the plaintext is returned once, when the token is issued.
+CREATE TABLE customer_email_verifications (
+    customer_id INTEGER PRIMARY KEY REFERENCES customers(id) ON DELETE CASCADE,
+    token_hash VARCHAR NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT now()
+);
@@ -0,0 +1,7 @@
+-- Emails are compared case-insensitively, so two customers may not hold
+-- emails that differ only in case. This fails on existing such pairs; start
+-- the server once with LOWERCASE_EMAILS_ON_STARTUP to list them, or find them with
+--   SELECT LOWER(email), COUNT(*) FROM customers GROUP BY LOWER(email) HAVING COUNT(*) > 1;
+-- and merge or fix them first.
+CREATE UNIQUE INDEX customers_email_lower_key
+    ON customers (LOWER(email));
@@ -0,0 +1,5 @@
+-- Each token is bound to the email it was issued for, so a token sent to a
+-- previous address cannot verify a new one. Pending tokens do not record
+-- their email and are discarded; customers ask for a new token.
+DELETE FROM customer_email_verifications;
+ALTER TABLE customer_email_verifications ADD COLUMN email VARCHAR NOT NULL;
@@ -0,0 +1,3 @@
+-- Requests authenticate by API token, so tokens are looked up by hash. The
+-- index also guarantees a token identifies exactly one customer.
+ALTER TABLE customer_api_tokens ADD CONSTRAINT customer_api_tokens_token_hash_key UNIQUE (token_hash);
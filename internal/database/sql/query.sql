@@ -2,16 +2,24 @@
 INSERT INTO customers (
     name,
     email,
-    password
+    password,
+    phone,
+    avatar_url
 )
-VALUES ($1, $2, $3)
+VALUES ($1, $2, $3, $4, $5)
 RETURNING
     id,
     name,
     email,
     password,
     created_at,
-    updated_at;
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified;
 
 
 
@@ -22,11 +30,36 @@ SELECT
     email,
     password,
     created_at,
-    updated_at
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified
 FROM customers
-WHERE id = $1
+WHERE id = $1 AND deleted_at IS NULL
 LIMIT 1;
 
+-- name: GetCustomerByIDForUpdate :one
+SELECT
+    id,
+    name,
+    email,
+    password,
+    created_at,
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified
+FROM customers
+WHERE id = $1 AND deleted_at IS NULL
+LIMIT 1
+FOR UPDATE;
+
 
 
 -- name: GetCustomerByEmail :one
@@ -36,9 +69,15 @@ SELECT
     email,
     password,
     created_at,
-    updated_at
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified
 FROM customers
-WHERE email = $1
+WHERE LOWER(email) = LOWER($1) AND deleted_at IS NULL
 LIMIT 1;
 
 
@@ -50,12 +89,138 @@ SELECT
     email,
     password,
     created_at,
-    updated_at
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified
+FROM customers
+WHERE deleted_at IS NULL
+  AND (sqlc.narg('is_active')::boolean IS NULL OR is_active = sqlc.narg('is_active'))
+  AND (sqlc.narg('tag')::text IS NULL OR EXISTS (
+      SELECT 1 FROM customer_tags
+      WHERE customer_tags.customer_id = customers.id AND customer_tags.tag = sqlc.narg('tag')
+  ))
+ORDER BY
+    CASE WHEN sqlc.arg('sort')::text = 'name' THEN name END,
+    CASE WHEN sqlc.arg('sort')::text = 'created_at' THEN created_at END,
+    id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+
+
+-- name: ListCustomersAfterID :many
+SELECT
+    id,
+    name,
+    email,
+    password,
+    created_at,
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified
 FROM customers
+WHERE deleted_at IS NULL AND id > $1
+ORDER BY id
+LIMIT $2;
+
+
+
+-- name: ListCustomersByEmails :many
+SELECT
+    id,
+    name,
+    email,
+    password,
+    created_at,
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified
+FROM customers
+WHERE deleted_at IS NULL AND LOWER(email) = ANY(sqlc.arg('emails')::text[])
 ORDER BY id;
 
 
 
+-- name: CountCustomers :one
+SELECT COUNT(*)
+FROM customers
+WHERE deleted_at IS NULL
+  AND (sqlc.narg('is_active')::boolean IS NULL OR is_active = sqlc.narg('is_active'))
+  AND (sqlc.narg('tag')::text IS NULL OR EXISTS (
+      SELECT 1 FROM customer_tags
+      WHERE customer_tags.customer_id = customers.id AND customer_tags.tag = sqlc.narg('tag')
+  ));
+
+-- name: SearchCustomers :many
+-- Matches the pattern against name or email; a customer whose email equals
+-- the query exactly ranks first.
+SELECT
+    id,
+    name,
+    email,
+    password,
+    created_at,
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified
+FROM customers
+WHERE deleted_at IS NULL
+  AND (name ILIKE sqlc.arg('pattern') OR email ILIKE sqlc.arg('pattern'))
+ORDER BY
+    LOWER(email) = LOWER(sqlc.arg('query')::text) DESC,
+    id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountSearchCustomers :one
+SELECT COUNT(*)
+FROM customers
+WHERE deleted_at IS NULL
+  AND (name ILIKE sqlc.arg('pattern') OR email ILIKE sqlc.arg('pattern'));
+
+
+
+-- name: GetCustomerStats :one
+SELECT
+    COUNT(*) AS total,
+    COUNT(*) FILTER (WHERE created_at >= NOW() - INTERVAL '24 hours') AS created_last_24h,
+    COUNT(*) FILTER (WHERE created_at >= NOW() - INTERVAL '7 days') AS created_last_7d,
+    COUNT(*) FILTER (WHERE created_at >= NOW() - INTERVAL '30 days') AS created_last_30d,
+    COUNT(*) FILTER (WHERE is_active) AS active,
+    COUNT(*) FILTER (WHERE NOT is_active) AS inactive
+FROM customers
+WHERE deleted_at IS NULL;
+
+
+
+-- name: CountSignupsByDay :many
+-- Counts every customer created in [since, until), deleted ones included,
+-- since a later deletion does not undo the signup. Days without signups
+-- are absent.
+SELECT
+    date_trunc('day', created_at)::date AS day,
+    COUNT(*) AS signups
+FROM customers
+WHERE created_at >= sqlc.arg('since') AND created_at < sqlc.arg('until')
+GROUP BY day
+ORDER BY day;
+
+
+
 -- name: UpdateCustomer :one
 UPDATE customers
 SET
@@ -63,6 +228,205 @@ SET
     name = $2,
     email = $3,
     password = $4,
+    email_verified = email_verified AND LOWER(email) = LOWER($3),
+    updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING
+    id,
+    name,
+    email,
+    password,
+    created_at,
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified;
+
+
+
+-- name: PatchCustomer :one
+UPDATE customers
+SET
+    name = COALESCE(sqlc.narg('name'), name),
+    email = COALESCE(sqlc.narg('email'), email),
+    password = COALESCE(sqlc.narg('password'), password),
+    phone = CASE WHEN sqlc.narg('phone')::text = '' THEN NULL ELSE COALESCE(sqlc.narg('phone'), phone) END,
+    avatar_url = CASE WHEN sqlc.narg('avatar_url')::text = '' THEN NULL ELSE COALESCE(sqlc.narg('avatar_url'), avatar_url) END,
+    email_verified = email_verified AND (sqlc.narg('email')::varchar IS NULL OR LOWER(email) = LOWER(sqlc.narg('email'))),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id') AND deleted_at IS NULL
+  AND (sqlc.narg('expected_updated_at')::timestamp IS NULL OR updated_at = sqlc.narg('expected_updated_at'))
+  AND (name, email, password, phone, avatar_url) IS DISTINCT FROM (
+      COALESCE(sqlc.narg('name'), name),
+      COALESCE(sqlc.narg('email'), email),
+      COALESCE(sqlc.narg('password'), password),
+      CASE WHEN sqlc.narg('phone')::text = '' THEN NULL ELSE COALESCE(sqlc.narg('phone'), phone) END,
+      CASE WHEN sqlc.narg('avatar_url')::text = '' THEN NULL ELSE COALESCE(sqlc.narg('avatar_url'), avatar_url) END
+  )
+RETURNING
+    id,
+    name,
+    email,
+    password,
+    created_at,
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified;
+
+
+
+-- name: UpdateCustomerName :one
+UPDATE customers
+SET
+    name = $2,
+    updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING
+    id,
+    name,
+    email,
+    password,
+    created_at,
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified;
+
+
+
+-- name: TouchCustomer :one
+-- Bumps updated_at alone, so caches keyed on it, ETags included, see a new
+-- version.
+UPDATE customers
+SET updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING updated_at;
+
+
+
+-- name: UpsertCustomerByEmail :one
+-- Creates the customer, or overwrites the live customer holding the email in
+-- any case.
+-- A soft-deleted holder is left alone and no row is returned. inserted
+-- tells the two outcomes apart: xmax is only zero for a freshly inserted row.
+INSERT INTO customers (
+    name,
+    email,
+    password,
+    phone,
+    avatar_url
+)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT ((LOWER(email))) DO UPDATE
+SET
+    name = EXCLUDED.name,
+    password = EXCLUDED.password,
+    phone = EXCLUDED.phone,
+    avatar_url = EXCLUDED.avatar_url,
+    updated_at = NOW()
+WHERE customers.deleted_at IS NULL
+RETURNING
+    id,
+    name,
+    email,
+    password,
+    created_at,
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified,
+    (xmax = 0) AS inserted;
+
+
+
+-- name: UpdateLastLogin :exec
+-- Leaves updated_at alone: a login is not a change to the customer, and
+-- bumping it would invalidate every ETag a client holds.
+UPDATE customers
+SET last_login_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL;
+
+
+
+-- name: SetCustomerActive :one
+UPDATE customers
+SET
+    is_active = $2,
+    updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING
+    id,
+    name,
+    email,
+    password,
+    created_at,
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified;
+
+
+
+-- name: SetCustomersActive :many
+UPDATE customers
+SET
+    is_active = sqlc.arg('is_active'),
+    updated_at = NOW()
+WHERE id = ANY(sqlc.arg('ids')::int[]) AND deleted_at IS NULL
+RETURNING id;
+
+
+
+-- name: SoftDeleteCustomer :one
+UPDATE customers
+SET
+    deleted_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING
+    id,
+    name,
+    email,
+    password,
+    created_at,
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified;
+
+
+
+-- name: AnonymizeCustomer :one
+UPDATE customers
+SET
+    name = $2,
+    email = $3,
+    password = $4,
+    phone = NULL,
+    avatar_url = NULL,
+    last_login_at = NULL,
+    email_verified = FALSE,
+    is_active = FALSE,
+    deleted_at = COALESCE(deleted_at, NOW()),
     updated_at = NOW()
 WHERE id = $1
 RETURNING
@@ -71,10 +435,120 @@ RETURNING
     email,
     password,
     created_at,
-    updated_at;
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified;
 
 
 
--- name: DeleteCustomerByEmail :execrows
+-- name: DeleteCustomerByEmail :many
 DELETE FROM customers
-WHERE email = $1;
\ No newline at end of file
+WHERE LOWER(email) = LOWER($1)
+RETURNING id;
+
+
+
+-- name: AddCustomerTag :exec
+INSERT INTO customer_tags (customer_id, tag)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+
+
+-- name: RemoveCustomerTag :exec
+DELETE FROM customer_tags
+WHERE customer_id = $1 AND tag = $2;
+
+
+
+-- name: ListCustomerTags :many
+SELECT tag
+FROM customer_tags
+WHERE customer_id = $1
+ORDER BY tag;
+
+
+
+-- name: MoveCustomerTags :exec
+WITH moved AS (
+    DELETE FROM customer_tags
+    WHERE customer_id = sqlc.arg('from_id')
+    RETURNING tag
+)
+INSERT INTO customer_tags (customer_id, tag)
+SELECT sqlc.arg('to_id')::int, tag FROM moved
+ON CONFLICT DO NOTHING;
+
+
+
+-- name: ExistsCustomerByID :one
+SELECT EXISTS (
+    SELECT 1 FROM customers
+    WHERE id = $1 AND deleted_at IS NULL
+);
+
+
+
+-- name: SetCustomerAPIToken :exec
+INSERT INTO customer_api_tokens (customer_id, token_hash)
+VALUES ($1, $2)
+ON CONFLICT (customer_id) DO UPDATE
+SET token_hash = EXCLUDED.token_hash, created_at = NOW();
+
+
+
+-- name: DeleteCustomerAPIToken :exec
+DELETE FROM customer_api_tokens
+WHERE customer_id = $1;
+
+
+
+-- name: GetCustomerIDByAPITokenHash :one
+SELECT t.customer_id, c.is_active
+FROM customer_api_tokens t
+JOIN customers c ON c.id = t.customer_id
+WHERE t.token_hash = $1 AND c.deleted_at IS NULL;
+
+
+
+-- name: SetEmailVerification :exec
+-- Replaces any pending verification, so only the newest token works.
+INSERT INTO customer_email_verifications (customer_id, token_hash, expires_at, email)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (customer_id) DO UPDATE
+SET token_hash = EXCLUDED.token_hash, expires_at = EXCLUDED.expires_at, email = EXCLUDED.email, created_at = NOW();
+
+-- name: GetEmailVerificationByTokenHash :one
+SELECT customer_id, token_hash, expires_at, created_at, email
+FROM customer_email_verifications
+WHERE token_hash = $1;
+
+-- name: DeleteEmailVerification :exec
+DELETE FROM customer_email_verifications
+WHERE customer_id = $1;
+
+-- name: MarkEmailVerified :one
+-- Only marks the customer while they still hold the email the token was
+-- issued for; after a change of email no row is returned.
+UPDATE customers
+SET
+    email_verified = TRUE,
+    updated_at = NOW()
+WHERE id = $1 AND LOWER(email) = LOWER(sqlc.arg('email')) AND deleted_at IS NULL
+RETURNING
+    id,
+    name,
+    email,
+    password,
+    created_at,
+    updated_at,
+    is_active,
+    deleted_at,
+    phone,
+    avatar_url,
+    last_login_at,
+    email_verified;
@@ -4,5 +4,36 @@ CREATE TABLE customers (
   email VARCHAR UNIQUE NOT NULL,
   password VARCHAR NOT NULL,
   created_at TIMESTAMP DEFAULT now(),
-  updated_at TIMESTAMP DEFAULT now()
-);
\ No newline at end of file
+  updated_at TIMESTAMP DEFAULT now(),
+  is_active BOOLEAN NOT NULL DEFAULT TRUE,
+  deleted_at TIMESTAMP,
+  phone VARCHAR,
+  avatar_url VARCHAR,
+  last_login_at TIMESTAMP,
+  email_verified BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE UNIQUE INDEX customers_email_lower_key ON customers (LOWER(email));
+
+CREATE TABLE customer_tags (
+  customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+  tag VARCHAR NOT NULL,
+  created_at TIMESTAMP DEFAULT now(),
+  PRIMARY KEY (customer_id, tag)
+);
+
+CREATE INDEX customer_tags_tag_idx ON customer_tags (tag);
+
+CREATE TABLE customer_api_tokens (
+  customer_id INTEGER PRIMARY KEY REFERENCES customers(id) ON DELETE CASCADE,
+  token_hash VARCHAR NOT NULL UNIQUE,
+  created_at TIMESTAMP DEFAULT now()
+);
+
+CREATE TABLE customer_email_verifications (
+  customer_id INTEGER PRIMARY KEY REFERENCES customers(id) ON DELETE CASCADE,
+  token_hash VARCHAR NOT NULL UNIQUE,
+  expires_at TIMESTAMP NOT NULL,
+  created_at TIMESTAMP DEFAULT now(),
+  email VARCHAR NOT NULL
+);
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/handler/dto"
+)
+
+// POST
+func (h *Handler) AnonymizeCustomer(w http.ResponseWriter, r *http.Request) {
+	// 1. Parse the customer ID from the path
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "invalid customer id", http.StatusBadRequest)
+		return
+	}
+
+	anonymized, err := h.service.AnonymizeCustomer(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, customer.ErrCustomerNotFound) {
+			h.notFound(w, r, "customer")
+			return
+		}
+		h.serverError(w, r, err, "could not anonymize customer")
+		return
+	}
+	resp := dto.NewCustomer(anonymized)
+	h.writeJSON(w, r, http.StatusOK, resp)
+}
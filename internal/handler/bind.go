@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// maxBodyBytes caps the size of any request body a handler will read
+const maxBodyBytes = 1 << 20
+
+// bindJSON decodes the JSON request body into dst. It caps the body size,
+// rejects unknown fields and non-JSON content types, and writes a specific
+// error response itself; callers simply return when it reports false.
+func bindJSON(w http.ResponseWriter, r *http.Request, dst any) bool {
+	return bindJSONAs(w, r, dst, "application/json")
+}
+
+// bindJSONAs is bindJSON for a body that must be sent as mediaType, a JSON
+// based format such as application/merge-patch+json
+func bindJSONAs(w http.ResponseWriter, r *http.Request, dst any, mediaType string) bool {
+	if contentType := r.Header.Get("Content-Type"); contentType != "" && requestMediaType(r) != mediaType {
+		http.Error(w, "content type must be "+mediaType, http.StatusUnsupportedMediaType)
+		return false
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	// Numbers decoded into untyped fields stay json.Number instead of float64,
+	// so large integers are not silently rounded
+	decoder.UseNumber()
+
+	if err := decoder.Decode(dst); err != nil {
+		status, msg := decodeErrorResponse(err)
+		http.Error(w, msg, status)
+		return false
+	}
+	if err := decoder.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		http.Error(w, "request body must contain a single JSON object", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// withinBatchLimit rejects a batch request carrying more than
+// Config.MaxBatchSize items with 400, and reports whether n is acceptable.
+// Call it after decoding and before any database work.
+func (h *Handler) withinBatchLimit(w http.ResponseWriter, n int) bool {
+	if n > h.cfg.MaxBatchSize {
+		http.Error(w, fmt.Sprintf("batch too large: at most %d items are allowed", h.cfg.MaxBatchSize), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// requestMediaType returns the media type of the request body without its
+// parameters, or "" when Content-Type is missing or malformed
+func requestMediaType(r *http.Request) string {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	return mediaType
+}
+
+// decodeErrorResponse maps a JSON decoding error to a status code and a
+// message that tells the client what is wrong with its body
+func decodeErrorResponse(err error) (int, string) {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.Is(err, io.EOF):
+		// Decode only reports a bare io.EOF when the body is empty
+		return http.StatusBadRequest, "request body is required"
+	case errors.As(err, &syntaxErr):
+		return http.StatusBadRequest, fmt.Sprintf("malformed JSON at position %d", syntaxErr.Offset)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return http.StatusBadRequest, "malformed JSON"
+	case errors.As(err, &typeErr) && strings.HasPrefix(typeErr.Value, "number") && isIntegerKind(typeErr.Type):
+		return http.StatusBadRequest, fmt.Sprintf("field %q must be an integer in the range of %s", typeErr.Field, typeErr.Type)
+	case errors.As(err, &typeErr):
+		return http.StatusBadRequest, fmt.Sprintf("invalid value for field %q", typeErr.Field)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		return http.StatusBadRequest, "unknown field " + field
+	case errors.As(err, &maxBytesErr):
+		return http.StatusRequestEntityTooLarge, fmt.Sprintf("request body must not be larger than %d bytes", maxBytesErr.Limit)
+	default:
+		return http.StatusBadRequest, "invalid request body"
+	}
+}
+
+// isIntegerKind reports whether t is a signed or unsigned integer type
+func isIntegerKind(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/ctxkeys"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+)
+
+type bulkUpdateCustomersRequest struct {
+	IDs   []int32 `json:"ids"`
+	Field string  `json:"field"`
+	Value any     `json:"value"`
+}
+
+type bulkUpdateResponse struct {
+	Updated int64 `json:"updated"`
+}
+
+type bulkUpdateEachResponse struct {
+	Updated int                `json:"updated"`
+	Failed  int                `json:"failed"`
+	Results []bulkItemResponse `json:"results"`
+}
+
+type bulkItemResponse struct {
+	ID     int32  `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// POST
+func (h *Handler) BulkUpdateCustomers(w http.ResponseWriter, r *http.Request) {
+	// 1. Parse the mode: atomic updates all customers in one statement,
+	// best_effort updates each independently and reports per-item results
+	mode := r.URL.Query().Get("mode")
+	if mode != "" && mode != "atomic" && mode != "best_effort" {
+		http.Error(w, "mode must be atomic or best_effort", http.StatusBadRequest)
+		return
+	}
+	// 2. Decode the JSON request
+	var request bulkUpdateCustomersRequest
+	if !bindJSON(w, r, &request) {
+		return
+	}
+	if len(request.IDs) == 0 {
+		http.Error(w, "ids are required", http.StatusBadRequest)
+		return
+	}
+	if !h.withinBatchLimit(w, len(request.IDs)) {
+		return
+	}
+
+	if mode == "best_effort" {
+		h.bulkUpdateEach(w, r, request)
+		return
+	}
+	updated, err := h.service.BulkUpdateField(r.Context(), request.IDs, request.Field, request.Value)
+	if err != nil {
+		h.bulkUpdateError(w, r, err)
+		return
+	}
+	resp := bulkUpdateResponse{Updated: updated}
+	h.writeJSON(w, r, http.StatusOK, resp)
+}
+
+// bulkUpdateEach runs a best-effort bulk update and answers 207 with the
+// outcome of every item
+func (h *Handler) bulkUpdateEach(w http.ResponseWriter, r *http.Request, request bulkUpdateCustomersRequest) {
+	results, err := h.service.BulkUpdateFieldEach(r.Context(), request.IDs, request.Field, request.Value)
+	if err != nil {
+		h.bulkUpdateError(w, r, err)
+		return
+	}
+	resp := bulkUpdateEachResponse{
+		Results: make([]bulkItemResponse, len(results)),
+	}
+	for i, result := range results {
+		item := bulkItemResponse{ID: result.ID, Status: "updated"}
+		switch {
+		case result.Err == nil:
+			resp.Updated++
+		case errors.Is(result.Err, customer.ErrCustomerNotFound):
+			item.Status, item.Error = "failed", "not_found"
+			resp.Failed++
+		default:
+			slog.ErrorContext(r.Context(), "bulk update item failed", "error", result.Err, "request_id", ctxkeys.RequestID(r.Context()))
+			item.Status, item.Error = "failed", "internal"
+			resp.Failed++
+		}
+		resp.Results[i] = item
+	}
+	h.writeJSON(w, r, http.StatusMultiStatus, resp)
+}
+
+func (h *Handler) bulkUpdateError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, customer.ErrBulkFieldNotAllowed):
+		http.Error(w, "field cannot be bulk updated", http.StatusBadRequest)
+	case errors.Is(err, customer.ErrBulkInvalidValue):
+		http.Error(w, "invalid value for field", http.StatusBadRequest)
+	default:
+		h.serverError(w, r, err, "could not update customers")
+	}
+}
@@ -1,62 +1,143 @@
 package handler
 
 import (
-	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
 	"net/http"
+	"time"
 
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/config"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/ctxkeys"
 	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
 	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/handler/dto"
 )
 
 type Handler struct {
 	service *customer.Service
+	cfg     *config.Config
+	// createDebounce is nil unless Config.CreateDebounceWindow is set
+	createDebounce *createDebouncer
 }
 
-func NewHandler(service *customer.Service) *Handler {
-	return &Handler{service: service}
+func NewHandler(service *customer.Service, cfg *config.Config) *Handler {
+	h := &Handler{service: service, cfg: cfg}
+	if cfg.CreateDebounceWindow > 0 {
+		h.createDebounce = newCreateDebouncer(cfg.CreateDebounceWindow)
+	}
+	return h
 }
 
 type createCustomerRequest struct {
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+	Phone     string `json:"phone"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+// createCustomerResponse is the created customer, plus its first email
+// verification token when EMAIL_VERIFICATION is on
+type createCustomerResponse struct {
+	dto.Customer
+	EmailVerificationToken     string     `json:"email_verification_token,omitempty"`
+	EmailVerificationExpiresAt *time.Time `json:"email_verification_expires_at,omitempty"`
+}
+
+// bindCreateCustomerRequest fills the request from a form-encoded body when
+// that content type is sent, and from JSON otherwise
+func bindCreateCustomerRequest(w http.ResponseWriter, r *http.Request, req *createCustomerRequest) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/x-www-form-urlencoded" {
+		return bindJSON(w, r, req)
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return false
+	}
+	req.Name = r.PostForm.Get("name")
+	req.Email = r.PostForm.Get("email")
+	req.Password = r.PostForm.Get("password")
+	req.Phone = r.PostForm.Get("phone")
+	req.AvatarURL = r.PostForm.Get("avatar_url")
+	return true
 }
 
 // POST
 func (h *Handler) CreateCustomer(w http.ResponseWriter, r *http.Request) {
-	// 1. Ensure method is POST
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-	// 2. Decode the JSON request
+	// 1. Decode the JSON or form-encoded request
 	var request createCustomerRequest
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+	if !bindCreateCustomerRequest(w, r, &request) {
 		return
 	}
 
-	// Map request to domain entity
-	customer := &database.Customer{
+	// 2. Map request to service input
+	input := customer.RegisterInput{
 		Name:     request.Name,
 		Email:    request.Email,
 		Password: request.Password,
+		Phone:    request.Phone,
+
+		AvatarURL: request.AvatarURL,
 	}
-	createdCustomer, err := h.service.CreateCustomer(r.Context(), customer.Name, customer.Email, customer.Password)
+
+	// 3. Answer a rapid identical resubmission with the first result
+	finish := func(*database.Customer) {}
+	if h.createDebounce != nil {
+		key := createDebounceKey(request.Email, request.Name, request.Password, ctxkeys.ClientIP(r.Context()))
+		var previous *database.Customer
+		previous, finish = h.createDebounce.begin(r.Context(), key)
+		if previous != nil {
+			h.writeCreatedCustomer(w, r, createCustomerResponse{Customer: dto.NewCustomer(previous)})
+			return
+		}
+	}
+	policy := customer.DuplicateEmailPolicy(h.cfg.DuplicateEmailPolicy)
+	createdCustomer, created, err := h.service.RegisterCustomerOnDuplicate(r.Context(), input, policy)
+	settle(r.Context(), finish, createdCustomer, err)
 	if err != nil {
-		http.Error(w, "could not create customer", http.StatusInternalServerError)
+		var invalid *customer.ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			h.validationFailed(w, r, invalid)
+		case errors.Is(err, customer.ErrEmailAlreadyExists):
+			http.Error(w, "email already exists", http.StatusConflict)
+		case errors.Is(err, customer.ErrNameAlreadyExists):
+			http.Error(w, "name already exists", http.StatusConflict)
+		case errors.Is(err, customer.ErrAlreadyExists):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			h.serverError(w, r, err, "could not create customer")
+		}
+		return
+	}
+	// 4. Under DUPLICATE_EMAIL_POLICY update or ignore, a taken email yields
+	// the existing customer, which is not new
+	if !created {
+		h.writeJSON(w, r, http.StatusOK, dto.NewCustomer(createdCustomer))
 		return
 	}
-	resp := struct {
-		ID    int32  `json:"id"`
-		Name  string `json:"name"`
-		Email string `json:"email"`
-	}{
-		ID:    createdCustomer.ID,
-		Name:  createdCustomer.Name,
-		Email: createdCustomer.Email,
-	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(resp)
+	resp := createCustomerResponse{Customer: dto.NewCustomer(createdCustomer)}
+
+	// 5. Issue the first verification token. The customer exists either way,
+	// so a failure here is logged and the client can ask for a token later.
+	if h.cfg.EmailVerification {
+		token, expiresAt, err := h.service.IssueEmailVerification(r.Context(), createdCustomer.ID, h.cfg.EmailVerificationTTL)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "could not issue email verification", "error", err, "customer_id", createdCustomer.ID, "request_id", ctxkeys.RequestID(r.Context()))
+		} else {
+			w.Header().Set("Cache-Control", "no-store")
+			resp.EmailVerificationToken = token
+			resp.EmailVerificationExpiresAt = &expiresAt
+		}
+	}
+	h.writeCreatedCustomer(w, r, resp)
+}
+
+func (h *Handler) writeCreatedCustomer(w http.ResponseWriter, r *http.Request, resp createCustomerResponse) {
+	w.Header().Set("Location", fmt.Sprintf("%s/customers/%d", h.cfg.BasePath, resp.ID))
+	h.writeJSON(w, r, http.StatusCreated, resp)
 }
@@ -0,0 +1,110 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/ctxkeys"
+)
+
+const janeBody = `{"name":"Jane Doe","email":"jane@example.com","password":"Very-Long-Passw0rd!xyz"}`
+
+func newDebouncedServer(t *testing.T, wrap func(http.Handler) http.Handler) *httptest.Server {
+	t.Helper()
+	cfg := testConfig()
+	cfg.CreateDebounceWindow = time.Minute
+	return newTestServerWith(t, cfg, wrap)
+}
+
+func createdID(t *testing.T, status int, body []byte) int32 {
+	t.Helper()
+	if status != http.StatusCreated {
+		t.Fatalf("status %d, want 201:\n%s", status, body)
+	}
+	var created struct{ ID int32 }
+	if err := json.Unmarshal(body, &created); err != nil {
+		t.Fatalf("decode created customer: %v", err)
+	}
+	return created.ID
+}
+
+func TestCreateCustomerDebouncesRapidDuplicates(t *testing.T) {
+	srv := newDebouncedServer(t, nil)
+
+	// Two identical requests in flight at once, as from a double-click
+	var wg sync.WaitGroup
+	statuses := make([]int, 2)
+	bodies := make([][]byte, 2)
+	for i := range statuses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statuses[i], bodies[i] = doJSON(t, srv, http.MethodPost, "/customers", janeBody)
+		}()
+	}
+	wg.Wait()
+	ids := []int32{createdID(t, statuses[0], bodies[0]), createdID(t, statuses[1], bodies[1])}
+	if ids[0] != ids[1] {
+		t.Fatalf("concurrent duplicates created customers %d and %d, want one", ids[0], ids[1])
+	}
+
+	// A resubmission after the first finished gets the same customer
+	status, body := doJSON(t, srv, http.MethodPost, "/customers", janeBody)
+	if id := createdID(t, status, body); id != ids[0] {
+		t.Errorf("resubmission got customer %d, want %d", id, ids[0])
+	}
+}
+
+func TestCreateCustomerDebounceKeysOnPassword(t *testing.T) {
+	srv := newDebouncedServer(t, nil)
+	status, body := doJSON(t, srv, http.MethodPost, "/customers", janeBody)
+	createdID(t, status, body)
+
+	// Someone else behind the same address with the same email and name
+	// must not be handed the first caller's customer
+	status, body = doJSON(t, srv, http.MethodPost, "/customers",
+		`{"name":"Jane Doe","email":"jane@example.com","password":"Different-Passw0rd!abc"}`)
+	if status != http.StatusConflict {
+		t.Fatalf("same email and name, other password: status %d, want 409:\n%s", status, body)
+	}
+}
+
+func TestCreateCustomerDebounceWaitsForCommit(t *testing.T) {
+	// failCommit stands in for the Transaction middleware when the commit
+	// fails: the handler answers 201, then the transaction rolls back
+	failCommit := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var afterCommit, afterRollback []func()
+			ctx := ctxkeys.WithAfterRollback(ctxkeys.WithAfterCommit(r.Context(), &afterCommit), &afterRollback)
+			next.ServeHTTP(discardResponse{header: make(http.Header)}, r.WithContext(ctx))
+			for _, fn := range afterRollback {
+				fn()
+			}
+			http.Error(w, "could not save changes", http.StatusInternalServerError)
+		})
+	}
+	srv := newDebouncedServer(t, failCommit)
+
+	if status, body := doJSON(t, srv, http.MethodPost, "/customers", janeBody); status != http.StatusInternalServerError {
+		t.Fatalf("first create: status %d, want 500:\n%s", status, body)
+	}
+	// The retry must reach the service rather than be answered from the
+	// debounce cache. The memory store has no real transaction, so the
+	// first customer is still there and the retry conflicts with it.
+	status, body := doJSON(t, srv, http.MethodPost, "/customers", janeBody)
+	if status == http.StatusCreated {
+		t.Fatalf("retry after a failed commit was answered 201 from the cache:\n%s", body)
+	}
+}
+
+// discardResponse drops what the handler writes, as the Transaction
+// middleware does when the commit fails
+type discardResponse struct{ header http.Header }
+
+func (d discardResponse) Header() http.Header         { return d.header }
+func (d discardResponse) Write(p []byte) (int, error) { return len(p), nil }
+func (d discardResponse) WriteHeader(int)             {}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/ctxkeys"
+	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+)
+
+// createDebouncer remembers recent creates so an identical resubmission, such
+// as a double-click, gets the first customer back instead of a 409. It is a
+// best-effort, per-process guard, not an idempotency key.
+type createDebouncer struct {
+	window  time.Duration
+	mu      sync.Mutex
+	entries map[string]*debounceEntry
+}
+
+type debounceEntry struct {
+	// done is closed once the first create finishes; customer is nil if it failed
+	done     chan struct{}
+	customer *database.Customer
+	expires  time.Time
+}
+
+func newCreateDebouncer(window time.Duration) *createDebouncer {
+	return &createDebouncer{window: window, entries: make(map[string]*debounceEntry)}
+}
+
+// createDebounceKey identifies "the same" create: same email, name, password
+// and client. The password keeps two people behind one address who submit
+// the same email and name from getting each other's customer; only its hash
+// is kept in memory.
+func createDebounceKey(email, name, password, clientIP string) string {
+	sum := sha256.Sum256([]byte(password))
+	return strings.ToLower(strings.TrimSpace(email)) + "\x00" + strings.TrimSpace(name) + "\x00" + hex.EncodeToString(sum[:]) + "\x00" + clientIP
+}
+
+// begin returns the customer created by an identical request within the
+// window, waiting for it if that request is still running. Otherwise the
+// caller goes ahead and must pass its outcome to finish, nil on failure,
+// once that outcome is durable; see settle.
+func (d *createDebouncer) begin(ctx context.Context, key string) (previous *database.Customer, finish func(*database.Customer)) {
+	now := time.Now()
+	d.mu.Lock()
+	for k, e := range d.entries {
+		if e.customer != nil && now.After(e.expires) {
+			delete(d.entries, k)
+		}
+	}
+	if e, ok := d.entries[key]; ok {
+		d.mu.Unlock()
+		select {
+		case <-e.done:
+			if e.customer != nil {
+				return e.customer, nil
+			}
+		case <-ctx.Done():
+		}
+		// The first request failed or is taking too long; let this one through
+		return nil, func(*database.Customer) {}
+	}
+	e := &debounceEntry{done: make(chan struct{})}
+	d.entries[key] = e
+	d.mu.Unlock()
+
+	return nil, func(c *database.Customer) {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if c == nil {
+			delete(d.entries, key)
+		} else {
+			e.customer = c
+			e.expires = time.Now().Add(d.window)
+		}
+		close(e.done)
+	}
+}
+
+// settle passes the outcome of a create to finish. Inside a request
+// transaction the customer only counts once the transaction commits; a
+// rollback releases the entry instead, so a retry is not answered with a
+// customer that was never stored.
+func settle(ctx context.Context, finish func(*database.Customer), c *database.Customer, err error) {
+	if err != nil {
+		finish(nil)
+		return
+	}
+	afterCommit, afterRollback := ctxkeys.AfterCommit(ctx), ctxkeys.AfterRollback(ctx)
+	if afterCommit == nil || afterRollback == nil {
+		finish(c)
+		return
+	}
+	*afterCommit = append(*afterCommit, func() { finish(c) })
+	*afterRollback = append(*afterRollback, func() { finish(nil) })
+}
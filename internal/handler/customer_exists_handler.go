@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"net/http"
+)
+
+// HEAD, reached through GetCustomerByID
+func (h *Handler) CustomerExists(w http.ResponseWriter, r *http.Request) {
+	// 1. Parse the customer ID from the path
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	exists, err := h.service.CustomerExists(r.Context(), id)
+	if err != nil {
+		h.serverError(w, r, err, "could not check customer")
+		return
+	}
+	if !exists {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
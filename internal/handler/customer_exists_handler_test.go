@@ -0,0 +1,38 @@
+package handler_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestHeadCustomer(t *testing.T) {
+	srv := newTestServer(t)
+	id := createTestCustomer(t, srv, "Jane Doe", "jane@example.com")
+	deleted := createTestCustomer(t, srv, "John Doe", "john@example.com")
+	if status, body := doJSON(t, srv, http.MethodDelete, fmt.Sprintf("/customers/%d", deleted), ""); status/100 != 2 {
+		t.Fatalf("delete: status %d:\n%s", status, body)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"existing", fmt.Sprintf("/customers/%d", id), http.StatusOK},
+		{"missing", "/customers/999999", http.StatusNotFound},
+		{"deleted", fmt.Sprintf("/customers/%d", deleted), http.StatusNotFound},
+		{"invalid id", "/customers/abc", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := send(t, srv, newRequest(t, srv, http.MethodHead, tt.path, "", ""))
+			if resp.StatusCode != tt.want {
+				t.Errorf("HEAD %s: status %d, want %d", tt.path, resp.StatusCode, tt.want)
+			}
+			if len(body) != 0 {
+				t.Errorf("HEAD %s: body %q, want none", tt.path, body)
+			}
+		})
+	}
+}
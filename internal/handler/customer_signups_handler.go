@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+)
+
+type dailySignupsResponse struct {
+	Date  string `json:"date"`
+	Count int64  `json:"count"`
+}
+
+// GET
+func (h *Handler) GetCustomerSignups(w http.ResponseWriter, r *http.Request) {
+	// 1. Parse the required ?from and ?to dates, both included
+	from, err := time.Parse(time.DateOnly, r.URL.Query().Get("from"))
+	if err != nil {
+		http.Error(w, "from must be a date such as 2024-01-31", http.StatusBadRequest)
+		return
+	}
+	to, err := time.Parse(time.DateOnly, r.URL.Query().Get("to"))
+	if err != nil {
+		http.Error(w, "to must be a date such as 2024-01-31", http.StatusBadRequest)
+		return
+	}
+
+	series, err := h.service.CustomerSignupsByDay(r.Context(), from, to)
+	if err != nil {
+		switch {
+		case errors.Is(err, customer.ErrInvalidDateRange),
+			errors.Is(err, customer.ErrDateRangeTooLarge):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			h.serverError(w, r, err, "could not count customer signups")
+		}
+		return
+	}
+
+	// 2. Map domain to response
+	resp := make([]dailySignupsResponse, len(series))
+	for i, day := range series {
+		resp[i] = dailySignupsResponse{Date: day.Date.Format(time.DateOnly), Count: day.Count}
+	}
+	h.writeJSON(w, r, http.StatusOK, resp)
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+)
+
+type customerStatsResponse struct {
+	Total          int64 `json:"total"`
+	CreatedLast24h int64 `json:"created_last_24h"`
+	CreatedLast7d  int64 `json:"created_last_7d"`
+	CreatedLast30d int64 `json:"created_last_30d"`
+	Active         int64 `json:"active"`
+	Inactive       int64 `json:"inactive"`
+}
+
+// GET
+func (h *Handler) GetCustomerStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.service.GetCustomerStats(r.Context())
+	if err != nil {
+		h.serverError(w, r, err, "could not fetch customer stats")
+		return
+	}
+	resp := customerStatsResponse{
+		Total:          stats.Total,
+		CreatedLast24h: stats.CreatedLast24h,
+		CreatedLast7d:  stats.CreatedLast7d,
+		CreatedLast30d: stats.CreatedLast30d,
+		Active:         stats.Active,
+		Inactive:       stats.Inactive,
+	}
+	h.writeJSON(w, r, http.StatusOK, resp)
+}
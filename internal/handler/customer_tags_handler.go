@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+)
+
+type customerTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+type customerTagsResponse struct {
+	ID   int32    `json:"id"`
+	Tags []string `json:"tags"`
+}
+
+// POST
+func (h *Handler) AddCustomerTags(w http.ResponseWriter, r *http.Request) {
+	h.updateCustomerTags(w, r, h.service.AddTags)
+}
+
+// DELETE
+func (h *Handler) RemoveCustomerTags(w http.ResponseWriter, r *http.Request) {
+	h.updateCustomerTags(w, r, h.service.RemoveTags)
+}
+
+// updateCustomerTags applies the tags in the request body to the customer in
+// the path and responds with the customer's resulting tags
+func (h *Handler) updateCustomerTags(w http.ResponseWriter, r *http.Request, apply func(context.Context, int32, []string) ([]string, error)) {
+	// 2. Parse the customer ID from the path
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "invalid customer id", http.StatusBadRequest)
+		return
+	}
+	// 3. Decode the JSON request
+	var request customerTagsRequest
+	if !bindJSON(w, r, &request) {
+		return
+	}
+	if !h.withinBatchLimit(w, len(request.Tags)) {
+		return
+	}
+
+	tags, err := apply(r.Context(), id, request.Tags)
+	if err != nil {
+		switch {
+		case errors.Is(err, customer.ErrCustomerNotFound):
+			h.notFound(w, r, "customer")
+		case errors.Is(err, customer.ErrNoTags),
+			errors.Is(err, customer.ErrInvalidTag):
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		default:
+			h.serverError(w, r, err, "could not update customer tags")
+		}
+		return
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+	resp := customerTagsResponse{
+		ID:   id,
+		Tags: tags,
+	}
+	h.writeJSON(w, r, http.StatusOK, resp)
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/handler/dto"
+)
+
+// deletedCustomerResponse is the customer as it was deleted
+type deletedCustomerResponse struct {
+	dto.Customer
+	DeletedAt string `json:"deleted_at"`
+}
+
+// DELETE
+func (h *Handler) DeleteCustomer(w http.ResponseWriter, r *http.Request) {
+	// 1. Parse the customer ID from the path
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "invalid customer id", http.StatusBadRequest)
+		return
+	}
+
+	deletedCustomer, err := h.service.DeleteCustomerByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, customer.ErrCustomerNotFound) {
+			h.notFound(w, r, "customer")
+			return
+		}
+		h.serverError(w, r, err, "could not delete customer")
+		return
+	}
+	if !h.cfg.DeleteReturnsRecord {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	// Returning the record lets clients confirm what was deleted or offer undo
+	resp := deletedCustomerResponse{
+		Customer:  dto.NewCustomer(deletedCustomer),
+		DeletedAt: deletedCustomer.DeletedAt.Time.Format(time.RFC3339),
+	}
+	h.writeJSON(w, r, http.StatusOK, resp)
+}
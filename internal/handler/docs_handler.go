@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+type docsResponse struct {
+	Routes []routeDoc `json:"routes"`
+}
+
+// fieldDoc describes one JSON field. Fields are listed rather than keyed by
+// name so StripPassword leaves a documented "password" field alone.
+type fieldDoc struct {
+	Name string `json:"name"`
+	Type any    `json:"type"`
+}
+
+type routeDoc struct {
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Summary  string `json:"summary"`
+	Request  any    `json:"request,omitempty"`
+	Response any    `json:"response,omitempty"`
+}
+
+// GET
+func (h *Handler) Docs(w http.ResponseWriter, r *http.Request) {
+	routes := h.Routes()
+	resp := docsResponse{Routes: make([]routeDoc, len(routes))}
+	for i, route := range routes {
+		resp.Routes[i] = routeDoc{
+			Method:   route.Method,
+			Path:     h.cfg.BasePath + route.Path,
+			Summary:  route.Summary,
+			Request:  describeShape(route.Request, false),
+			Response: describeShape(route.Response, true),
+		}
+	}
+	h.writeJSON(w, r, http.StatusOK, resp)
+}
+
+var jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+
+// describeShape maps a body type to its JSON fields and value types, in
+// declaration order. Password fields are left out of responses, which never
+// carry them.
+func describeShape(v any, response bool) any {
+	if v == nil {
+		return nil
+	}
+	return shapeOf(reflect.TypeOf(v), response)
+}
+
+func shapeOf(t reflect.Type, response bool) any {
+	if t.Implements(jsonMarshalerType) {
+		return "string"
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return shapeOf(t.Elem(), response)
+	case reflect.Slice, reflect.Array:
+		return []any{shapeOf(t.Elem(), response)}
+	case reflect.Struct:
+		return structFields(t, response)
+	case reflect.Map:
+		return "object"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	default:
+		return "any"
+	}
+}
+
+func structFields(t reflect.Type, response bool) []fieldDoc {
+	var fields []fieldDoc
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, structFields(field.Type, response)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		if response && strings.EqualFold(name, "password") {
+			continue
+		}
+		fields = append(fields, fieldDoc{Name: name, Type: shapeOf(field.Type, response)})
+	}
+	return fields
+}
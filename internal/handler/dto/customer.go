@@ -0,0 +1,104 @@
+// Package dto maps database rows to the JSON shapes the API returns. Every
+// response that carries customer data is built here, so a column added to
+// database.Customer, such as the password hash, only reaches clients once a
+// mapping below names it.
+package dto
+
+import (
+	"time"
+
+	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Customer is the full public view of a customer returned by every endpoint
+// that answers with customers. Handlers must never encode a
+// database.Customer directly.
+type Customer struct {
+	ID            int32      `json:"id"`
+	Name          string     `json:"name"`
+	Email         string     `json:"email"`
+	Phone         *string    `json:"phone"`
+	AvatarURL     *string    `json:"avatar_url"`
+	IsActive      bool       `json:"is_active"`
+	LastLoginAt   *time.Time `json:"last_login_at"`
+	EmailVerified bool       `json:"email_verified"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	// Version changes on every write; send it quoted in If-Match to make an
+	// update conditional
+	Version int64 `json:"version"`
+}
+
+func NewCustomer(c *database.Customer) Customer {
+	return Customer{
+		ID:            c.ID,
+		Name:          c.Name,
+		Email:         c.Email,
+		Phone:         nullableText(c.Phone),
+		AvatarURL:     nullableText(c.AvatarUrl),
+		IsActive:      c.IsActive,
+		LastLoginAt:   nullableTime(c.LastLoginAt),
+		EmailVerified: c.EmailVerified,
+		CreatedAt:     c.CreatedAt.Time,
+		UpdatedAt:     c.UpdatedAt.Time,
+		Version:       Version(c.UpdatedAt.Time),
+	}
+}
+
+// NewCustomers maps a page of customers, keeping their order
+func NewCustomers(customers []database.Customer) []Customer {
+	resp := make([]Customer, len(customers))
+	for i := range customers {
+		resp[i] = NewCustomer(&customers[i])
+	}
+	return resp
+}
+
+// ExportedCustomer is one line of an NDJSON export. It leaves out the
+// timestamps and version, which only matter to clients that write back.
+type ExportedCustomer struct {
+	ID            int32      `json:"id"`
+	Name          string     `json:"name"`
+	Email         string     `json:"email"`
+	Phone         *string    `json:"phone"`
+	AvatarURL     *string    `json:"avatar_url"`
+	IsActive      bool       `json:"is_active"`
+	LastLoginAt   *time.Time `json:"last_login_at"`
+	EmailVerified bool       `json:"email_verified"`
+}
+
+func NewExportedCustomer(c *database.Customer) ExportedCustomer {
+	return ExportedCustomer{
+		ID:            c.ID,
+		Name:          c.Name,
+		Email:         c.Email,
+		Phone:         nullableText(c.Phone),
+		AvatarURL:     nullableText(c.AvatarUrl),
+		IsActive:      c.IsActive,
+		LastLoginAt:   nullableTime(c.LastLoginAt),
+		EmailVerified: c.EmailVerified,
+	}
+}
+
+// Version is the version returned in customer responses: updated_at in
+// microseconds, the precision it is stored with, which every write bumps
+func Version(updatedAt time.Time) int64 {
+	return updatedAt.UnixMicro()
+}
+
+// nullableText maps a nullable column to a pointer so unset values encode as null
+func nullableText(t pgtype.Text) *string {
+	if !t.Valid {
+		return nil
+	}
+	return &t.String
+}
+
+// nullableTime maps a nullable timestamp to a pointer so unset values encode as null
+func nullableTime(t pgtype.Timestamp) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -0,0 +1,149 @@
+package dto
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+const passwordHash = "$2a$10$not.a.real.hash.but.must.never.leak"
+
+func fixtureCustomer() *database.Customer {
+	created := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	updated := time.Date(2024, 3, 2, 10, 0, 0, 123456000, time.UTC)
+	return &database.Customer{
+		ID:            42,
+		Name:          "Jane Doe",
+		Email:         "jane@example.com",
+		Password:      passwordHash,
+		CreatedAt:     pgtype.Timestamp{Time: created, Valid: true},
+		UpdatedAt:     pgtype.Timestamp{Time: updated, Valid: true},
+		IsActive:      true,
+		Phone:         pgtype.Text{String: "+15551234567", Valid: true},
+		AvatarUrl:     pgtype.Text{},
+		LastLoginAt:   pgtype.Timestamp{Time: updated, Valid: true},
+		EmailVerified: true,
+	}
+}
+
+// TestShapes pins the JSON of every customer shape the API returns. Run
+// with -update after an intended change and review the golden diff.
+func TestShapes(t *testing.T) {
+	c := fixtureCustomer()
+	tests := []struct {
+		name  string
+		value any
+	}{
+		{"customer", NewCustomer(c)},
+		{"customers", NewCustomers([]database.Customer{*c})},
+		{"customer_compact", ProjectCustomer(c, CompactCustomerFields)},
+		{"customer_all_fields", ProjectCustomer(c, allCustomerFields())},
+		{"exported_customer", NewExportedCustomer(c)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.MarshalIndent(tt.value, "", "  ")
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			got = append(got, '\n')
+			if bytes.Contains(got, []byte("password")) || bytes.Contains(got, []byte(passwordHash)) {
+				t.Fatalf("password leaked into the response:\n%s", got)
+			}
+
+			path := filepath.Join("testdata", tt.name+".golden.json")
+			if *update {
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatalf("write golden file: %v", err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read golden file (run with -update to create it): %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s does not match:\ngot:\n%s\nwant:\n%s", path, got, want)
+			}
+		})
+	}
+}
+
+// TestProjectionMatchesFullView keeps ?fields in step with the full view:
+// every field of Customer is selectable and encodes the same way
+func TestProjectionMatchesFullView(t *testing.T) {
+	c := fixtureCustomer()
+	full := toMap(t, NewCustomer(c))
+	projected := toMap(t, ProjectCustomer(c, allCustomerFields()))
+	if len(full) != len(projected) {
+		t.Fatalf("full view has %d fields, projection %d", len(full), len(projected))
+	}
+	for name, want := range full {
+		got, ok := projected[name]
+		if !ok {
+			t.Errorf("field %q cannot be selected with ?fields", name)
+			continue
+		}
+		if string(got) != string(want) {
+			t.Errorf("field %q: projection encodes %s, full view %s", name, got, want)
+		}
+	}
+}
+
+func TestNullableFields(t *testing.T) {
+	c := fixtureCustomer()
+	c.Phone = pgtype.Text{}
+	c.LastLoginAt = pgtype.Timestamp{}
+	got := toMap(t, NewCustomer(c))
+	for _, name := range []string{"phone", "avatar_url", "last_login_at"} {
+		if string(got[name]) != "null" {
+			t.Errorf("%s = %s, want null", name, got[name])
+		}
+	}
+}
+
+func TestIsCustomerField(t *testing.T) {
+	for _, name := range []string{"id", "email", "version"} {
+		if !IsCustomerField(name) {
+			t.Errorf("IsCustomerField(%q) = false, want true", name)
+		}
+	}
+	for _, name := range []string{"password", "deleted_at", "", "ID"} {
+		if IsCustomerField(name) {
+			t.Errorf("IsCustomerField(%q) = true, want false", name)
+		}
+	}
+}
+
+func allCustomerFields() []string {
+	fields := make([]string, 0, len(customerFields))
+	for name := range customerFields {
+		fields = append(fields, name)
+	}
+	return fields
+}
+
+func toMap(t *testing.T, v any) map[string]json.RawMessage {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if strings.Contains(string(data), "password") {
+		t.Fatalf("password leaked: %s", data)
+	}
+	return m
+}
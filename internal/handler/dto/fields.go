@@ -0,0 +1,41 @@
+package dto
+
+import (
+	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+)
+
+// customerFields are the fields of Customer a client may select with
+// ?fields, each encoded as it is in the full view
+var customerFields = map[string]func(c *database.Customer) any{
+	"id":             func(c *database.Customer) any { return c.ID },
+	"name":           func(c *database.Customer) any { return c.Name },
+	"email":          func(c *database.Customer) any { return c.Email },
+	"phone":          func(c *database.Customer) any { return nullableText(c.Phone) },
+	"avatar_url":     func(c *database.Customer) any { return nullableText(c.AvatarUrl) },
+	"is_active":      func(c *database.Customer) any { return c.IsActive },
+	"last_login_at":  func(c *database.Customer) any { return nullableTime(c.LastLoginAt) },
+	"email_verified": func(c *database.Customer) any { return c.EmailVerified },
+	"created_at":     func(c *database.Customer) any { return c.CreatedAt.Time },
+	"updated_at":     func(c *database.Customer) any { return c.UpdatedAt.Time },
+	"version":        func(c *database.Customer) any { return Version(c.UpdatedAt.Time) },
+}
+
+// CompactCustomerFields are the fields of the compact view, which mobile
+// clients list customers with
+var CompactCustomerFields = []string{"id", "name"}
+
+// IsCustomerField reports whether name can be selected with ?fields
+func IsCustomerField(name string) bool {
+	_, ok := customerFields[name]
+	return ok
+}
+
+// ProjectCustomer builds a response holding only the requested fields, which
+// must all pass IsCustomerField
+func ProjectCustomer(c *database.Customer, fields []string) map[string]any {
+	projected := make(map[string]any, len(fields))
+	for _, field := range fields {
+		projected[field] = customerFields[field](c)
+	}
+	return projected
+}
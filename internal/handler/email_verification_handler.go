@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/handler/dto"
+)
+
+type emailVerificationResponse struct {
+	ID        int32     `json:"id"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type verifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// POST
+func (h *Handler) IssueEmailVerification(w http.ResponseWriter, r *http.Request) {
+	// 1. Parse the customer ID from the path
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "invalid customer id", http.StatusBadRequest)
+		return
+	}
+
+	token, expiresAt, err := h.service.IssueEmailVerification(r.Context(), id, h.cfg.EmailVerificationTTL)
+	if err != nil {
+		switch {
+		case errors.Is(err, customer.ErrCustomerNotFound):
+			h.notFound(w, r, "customer")
+		case errors.Is(err, customer.ErrEmailAlreadyVerified):
+			http.Error(w, customer.ErrEmailAlreadyVerified.Error(), http.StatusConflict)
+		default:
+			h.serverError(w, r, err, "could not issue email verification")
+		}
+		return
+	}
+	// 2. The plaintext is only ever shown in this response; keep it out of caches
+	w.Header().Set("Cache-Control", "no-store")
+	resp := emailVerificationResponse{
+		ID:        id,
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}
+	h.writeJSON(w, r, http.StatusCreated, resp)
+}
+
+// POST
+func (h *Handler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	// 1. Decode the JSON request
+	var request verifyEmailRequest
+	if !bindJSON(w, r, &request) {
+		return
+	}
+
+	verified, err := h.service.VerifyEmail(r.Context(), request.Token)
+	if err != nil {
+		if errors.Is(err, customer.ErrInvalidVerificationToken) {
+			http.Error(w, customer.ErrInvalidVerificationToken.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		h.serverError(w, r, err, "could not verify email")
+		return
+	}
+	resp := dto.NewCustomer(verified)
+	h.writeJSON(w, r, http.StatusOK, resp)
+}
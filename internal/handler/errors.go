@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/ctxkeys"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/database"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/middleware"
+)
+
+// serverError logs an unexpected failure under msg and answers 500 with only
+// the request ID, so database details never reach the client. A closed pool
+// only happens during shutdown, so that case gets 503 and Retry-After instead.
+// An exhausted pool is load the client should back off from, a lost database
+// connection is transient, and a request that ran past its deadline is not a
+// bug, so all three get 503 and Retry-After too.
+func (h *Handler) serverError(w http.ResponseWriter, r *http.Request, err error, msg string) {
+	if errors.Is(err, database.ErrPoolClosed) {
+		middleware.RetryAfter(w, 5*time.Second)
+		http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if errors.Is(err, context.DeadlineExceeded) && r.Context().Err() != nil {
+		slog.WarnContext(r.Context(), msg, "error", err, "request_id", ctxkeys.RequestID(r.Context()))
+		middleware.RetryAfter(w, time.Second)
+		http.Error(w, "request timed out", http.StatusServiceUnavailable)
+		return
+	}
+	if database.IsConnectionLost(err) {
+		slog.WarnContext(r.Context(), msg, "error", err, "request_id", ctxkeys.RequestID(r.Context()))
+		middleware.RetryAfter(w, time.Second)
+		http.Error(w, "database connection lost, retry later", http.StatusServiceUnavailable)
+		return
+	}
+	if errors.Is(err, database.ErrPoolTimeout) {
+		slog.WarnContext(r.Context(), msg, "error", err, "request_id", ctxkeys.RequestID(r.Context()))
+		middleware.RetryAfter(w, time.Second)
+		http.Error(w, "server is busy, retry later", http.StatusServiceUnavailable)
+		return
+	}
+	requestID := ctxkeys.RequestID(r.Context())
+	slog.ErrorContext(r.Context(), msg, "error", err, "request_id", requestID)
+	h.writeJSON(w, r, http.StatusInternalServerError, errorResponse{Error: errorDetail{Code: "internal", RequestID: requestID}})
+}
+
+type errorResponse struct {
+	Error errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	Code      string             `json:"code"`
+	Resource  string             `json:"resource,omitempty"`
+	RequestID string             `json:"request_id,omitempty"`
+	Fields    []fieldErrorDetail `json:"fields,omitempty"`
+}
+
+type fieldErrorDetail struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// notFound writes the 404 body shared by every entity lookup
+func (h *Handler) notFound(w http.ResponseWriter, r *http.Request, resource string) {
+	h.writeJSON(w, r, http.StatusNotFound, errorResponse{Error: errorDetail{Code: "not_found", Resource: resource}})
+}
+
+// validationFailed answers 422 listing every rejected field, so a form can
+// mark them all in one round trip
+func (h *Handler) validationFailed(w http.ResponseWriter, r *http.Request, invalid *customer.ValidationError) {
+	detail := errorDetail{Code: "validation_failed", Fields: make([]fieldErrorDetail, len(invalid.Fields))}
+	for i, field := range invalid.Fields {
+		detail.Fields[i] = fieldErrorDetail{Field: field.Field, Message: field.Err.Error()}
+	}
+	h.writeJSON(w, r, http.StatusUnprocessableEntity, errorResponse{Error: detail})
+}
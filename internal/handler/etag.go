@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/handler/dto"
+)
+
+var errMalformedIfMatch = errors.New("malformed If-Match header")
+
+// customerETag derives a strong ETag from the customer version, so the
+// version a client read is also the value it sends back in If-Match
+func customerETag(c *database.Customer) string {
+	return updatedAtETag(c.UpdatedAt.Time)
+}
+
+// updatedAtETag is customerETag for when only updated_at is at hand
+func updatedAtETag(updatedAt time.Time) string {
+	return `"` + strconv.FormatInt(dto.Version(updatedAt), 10) + `"`
+}
+
+// parseIfMatch returns the updated_at value named by an If-Match header,
+// which carries a quoted ETag or customer version such as "1718000000000000".
+// It returns nil when the header is absent or "*", which matches any version.
+func parseIfMatch(r *http.Request) (*time.Time, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return nil, nil
+	}
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return nil, errMalformedIfMatch
+	}
+	micros, err := strconv.ParseInt(value[1:len(value)-1], 10, 64)
+	if err != nil {
+		return nil, errMalformedIfMatch
+	}
+	updatedAt := time.UnixMicro(micros).UTC()
+	return &updatedAt, nil
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+
+	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/handler/dto"
+)
+
+const exportBatchSize = 500
+
+// Values accepted in ?format and ?compress. NDJSON is the only format, and
+// the parameter exists so others can be added without a new endpoint.
+const (
+	exportFormatNDJSON   = "ndjson"
+	exportCompressGzip   = "gzip"
+	exportCompressNone   = "none"
+	exportFilenameNDJSON = "customers.ndjson"
+)
+
+// GET
+func (h *Handler) ExportCustomers(w http.ResponseWriter, r *http.Request) {
+	// 1. Validate the format and compression before anything is sent
+	switch r.URL.Query().Get("format") {
+	case "", exportFormatNDJSON:
+	default:
+		http.Error(w, "format must be ndjson", http.StatusBadRequest)
+		return
+	}
+	compress := r.URL.Query().Get("compress")
+	switch compress {
+	case "", exportCompressNone, exportCompressGzip:
+	default:
+		http.Error(w, "compress must be gzip or none", http.StatusBadRequest)
+		return
+	}
+
+	// 2. Gzip, when asked for, is a Content-Encoding of the NDJSON body, so
+	// the saved file is still customers.ndjson once the client decodes it
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+exportFilenameNDJSON+`"`)
+	var out io.Writer = w
+	flush := func() error { return nil }
+	if compress == exportCompressGzip {
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		defer zw.Close()
+		out = zw
+		flush = zw.Flush
+	}
+
+	// 3. Stream one JSON object per line, batch by batch, flushing after each
+	// batch so the client receives data while the export runs
+	controller := http.NewResponseController(w)
+	encoder := json.NewEncoder(out)
+	err := h.service.IterateCustomers(r.Context(), exportBatchSize, func(batch []database.Customer) error {
+		for _, c := range batch {
+			if err := encoder.Encode(dto.NewExportedCustomer(&c)); err != nil {
+				return err
+			}
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		// Writers that cannot flush still get the whole body at the end
+		_ = controller.Flush()
+		return nil
+	})
+	if err != nil {
+		// The status line is already sent, so all we can do is stop and log
+		log.Println("export customers:", err)
+	}
+}
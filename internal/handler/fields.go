@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/handler/dto"
+)
+
+// parseFields validates a comma-separated ?fields value. A nil result means
+// no projection was requested.
+func parseFields(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	fields := strings.Split(value, ",")
+	for i, field := range fields {
+		field = strings.TrimSpace(field)
+		if !dto.IsCustomerField(field) {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+		fields[i] = field
+	}
+	return fields, nil
+}
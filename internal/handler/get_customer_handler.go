@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+	database "github.com/Amir-Golmoradi/Customer-Management-System/internal/database/generated"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/handler/dto"
+)
+
+// GET, HEAD
+func (h *Handler) GetCustomerByID(w http.ResponseWriter, r *http.Request) {
+	// 1. HEAD is a cheap existence check. The mux routes HEAD here because a
+	// separate HEAD pattern would conflict with GET routes
+	if r.Method == http.MethodHead {
+		h.CustomerExists(w, r)
+		return
+	}
+	// 2. Parse the customer ID from the path
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "invalid customer id", http.StatusBadRequest)
+		return
+	}
+
+	// 3. Parse the optional field projection
+	fields, err := parseFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	foundCustomer, err := h.service.GetCustomerByID(r.Context(), id)
+	h.writeCustomerLookup(w, r, foundCustomer, fields, err)
+}
+
+// GET
+func (h *Handler) GetCustomerByEmail(w http.ResponseWriter, r *http.Request) {
+	// 1. Read the email from the query string
+	email := r.URL.Query().Get("email")
+	if email == "" {
+		http.Error(w, "email is required", http.StatusBadRequest)
+		return
+	}
+
+	// 2. Parse the optional field projection
+	fields, err := parseFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	foundCustomer, err := h.service.GetCustomerByEmail(r.Context(), email)
+	h.writeCustomerLookup(w, r, foundCustomer, fields, err)
+}
+
+// GET
+func (h *Handler) GetCustomerByEmailOrID(w http.ResponseWriter, r *http.Request) {
+	// 1. Read the identifier from the query string
+	identifier := r.URL.Query().Get("id_or_email")
+	if identifier == "" {
+		http.Error(w, "id_or_email is required", http.StatusBadRequest)
+		return
+	}
+
+	// 2. Parse the optional field projection
+	fields, err := parseFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	foundCustomer, err := h.service.GetCustomerByEmailOrID(r.Context(), identifier)
+	if errors.Is(err, customer.ErrInvalidIdentifier) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.writeCustomerLookup(w, r, foundCustomer, fields, err)
+}
+
+// writeCustomerLookup writes the result of a single-customer lookup, turning
+// ErrCustomerNotFound into the shared 404 body and applying any field projection
+func (h *Handler) writeCustomerLookup(w http.ResponseWriter, r *http.Request, c *database.Customer, fields []string, err error) {
+	if err != nil {
+		if errors.Is(err, customer.ErrCustomerNotFound) {
+			h.notFound(w, r, "customer")
+			return
+		}
+		h.serverError(w, r, err, "could not fetch customer")
+		return
+	}
+	w.Header().Set("ETag", customerETag(c))
+	if fields != nil {
+		h.writeJSON(w, r, http.StatusOK, dto.ProjectCustomer(c, fields))
+		return
+	}
+	resp := dto.NewCustomer(c)
+	h.writeJSON(w, r, http.StatusOK, resp)
+}
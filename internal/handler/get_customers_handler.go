@@ -1,10 +1,24 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
+	"strconv"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/handler/dto"
+)
+
+// Views accepted in ?view. Compact is meant for mobile clients: it returns
+// only id and name, in smaller pages, and skips counting the total, so
+// X-Total-Count and Link are only sent when ?meta=true asks for them.
+const (
+	viewFull    = "full"
+	viewCompact = "compact"
 )
 
+// compactPageSize is the default page size of the compact view
+const compactPageSize = 10
+
 type getCustomerRequest struct {
 	ID    int32  `json:"id"`
 	Name  string `json:"name"`
@@ -12,26 +26,105 @@ type getCustomerRequest struct {
 }
 
 func (h *Handler) GetCustomers(w http.ResponseWriter, r *http.Request) {
-	// 1. Ensure method in GET
-	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	// 1. Parse the optional active status filter
+	active, err := parseActiveFilter(r.URL.Query().Get("active"))
+	if err != nil {
+		http.Error(w, "active must be true, false or all", http.StatusBadRequest)
 		return
 	}
 
-	// 2. Map domain to response
+	// 2. Parse the view and the requested page, which is smaller by default
+	// in the compact view
+	view := r.URL.Query().Get("view")
+	defaultPageSize := h.cfg.DefaultPageSize
+	switch view {
+	case "", viewFull:
+	case viewCompact:
+		defaultPageSize = min(compactPageSize, defaultPageSize)
+	default:
+		http.Error(w, "view must be full or compact", http.StatusBadRequest)
+		return
+	}
+	withMeta := view != viewCompact || r.URL.Query().Get("meta") == "true"
+	page, err := h.parsePaginationDefault(r.URL.Query(), defaultPageSize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// 3. Build the list filter, falling back to the configured default sort
+	sortName := r.URL.Query().Get("sort")
+	if sortName == "" {
+		sortName = h.cfg.DefaultSort
+	}
+	filter := customer.ListFilter{
+		Active: active,
+		Tag:    r.URL.Query().Get("tag"),
+		Sort:   customer.ListSort(sortName),
+		Limit:  page.limit(),
+		Offset: page.offset(),
+	}
+	if err := filter.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// 4. Parse the optional field projection, which the compact view fixes
+	fields, err := parseFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if view == viewCompact {
+		if fields != nil {
+			http.Error(w, "fields cannot be combined with view=compact", http.StatusBadRequest)
+			return
+		}
+		fields = dto.CompactCustomerFields
+	}
+
+	// 5. Map domain to response
 	// var request getCustomerRequest
 	// customer := &database.Customer{
 	// 	ID:    request.ID,
 	// 	Name:  request.Name,
 	// 	Email: request.Email,
 	// }
-	customers, err := h.service.GetCustomers(r.Context())
+	customers, err := h.service.ListCustomers(r.Context(), filter)
 	if err != nil {
-		http.Error(w, "failed to fetch customers: "+err.Error(), http.StatusInternalServerError)
+		h.serverError(w, r, err, "could not fetch customers")
+		return
+	}
+	if withMeta {
+		total, err := h.service.CountCustomers(r.Context(), filter)
+		if err != nil {
+			h.serverError(w, r, err, "could not fetch customers")
+			return
+		}
+		h.setPaginationHeaders(w, r, page, total)
+	}
+
+	if fields != nil {
+		projected := make([]map[string]any, len(customers))
+		for i := range customers {
+			projected[i] = dto.ProjectCustomer(&customers[i], fields)
+		}
+		h.writeJSON(w, r, http.StatusOK, projected)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+	h.writeJSON(w, r, http.StatusOK, dto.NewCustomers(customers))
+}
 
-	json.NewEncoder(w).Encode(customers)
+// parseActiveFilter maps the ?active query value to a status filter,
+// where nil means customers of any status
+func parseActiveFilter(value string) (*bool, error) {
+	switch value {
+	case "", "all":
+		return nil, nil
+	}
+	active, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, err
+	}
+	return &active, nil
 }
@@ -0,0 +1,144 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestGetCustomersCompactView(t *testing.T) {
+	srv := newTestServer(t)
+	for i := range 12 {
+		createTestCustomer(t, srv, fmt.Sprintf("Customer %02d", i), fmt.Sprintf("customer%02d@example.com", i))
+	}
+
+	list := func(t *testing.T, query string) (*http.Response, []map[string]any) {
+		t.Helper()
+		resp, body := send(t, srv, newRequest(t, srv, http.MethodGet, "/customer"+query, "", ""))
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET /customer%s: status %d, want 200:\n%s", query, resp.StatusCode, body)
+		}
+		// A bare array: decoding fails if the page is wrapped in an envelope
+		var items []map[string]any
+		if err := json.Unmarshal(body, &items); err != nil {
+			t.Fatalf("GET /customer%s: body is not a JSON array: %v\n%s", query, err, body)
+		}
+		return resp, items
+	}
+
+	t.Run("compact", func(t *testing.T) {
+		resp, items := list(t, "?view=compact")
+		if len(items) != 10 {
+			t.Errorf("got %d customers, want the compact default page of 10", len(items))
+		}
+		for _, item := range items {
+			keys := make([]string, 0, len(item))
+			for key := range item {
+				keys = append(keys, key)
+			}
+			slices.Sort(keys)
+			if !slices.Equal(keys, []string{"id", "name"}) {
+				t.Fatalf("compact customer has keys %v, want [id name]", keys)
+			}
+		}
+		for _, header := range []string{"X-Total-Count", "Link"} {
+			if got := resp.Header.Get(header); got != "" {
+				t.Errorf("%s = %q, want none without ?meta=true", header, got)
+			}
+		}
+	})
+
+	t.Run("compact with meta", func(t *testing.T) {
+		resp, items := list(t, "?view=compact&meta=true")
+		if len(items) != 10 {
+			t.Errorf("got %d customers, want 10", len(items))
+		}
+		if got := resp.Header.Get("X-Total-Count"); got != "12" {
+			t.Errorf("X-Total-Count = %q, want 12", got)
+		}
+		if resp.Header.Get("Link") == "" {
+			t.Error("missing Link with ?meta=true")
+		}
+	})
+
+	t.Run("compact with page size", func(t *testing.T) {
+		if _, items := list(t, "?view=compact&page_size=5"); len(items) != 5 {
+			t.Errorf("got %d customers, want 5", len(items))
+		}
+		if _, items := list(t, "?view=compact&page=2"); len(items) != 2 {
+			t.Errorf("page 2 has %d customers, want the 2 left after 10", len(items))
+		}
+	})
+
+	t.Run("full", func(t *testing.T) {
+		resp, items := list(t, "")
+		if len(items) != 12 {
+			t.Errorf("got %d customers, want all 12 within the default page of 20", len(items))
+		}
+		if _, ok := items[0]["email"]; !ok {
+			t.Errorf("full customer has no email: %v", items[0])
+		}
+		if got := resp.Header.Get("X-Total-Count"); got != "12" {
+			t.Errorf("X-Total-Count = %q, want 12", got)
+		}
+	})
+
+	for _, query := range []string{"?view=compact&fields=id,email", "?view=tiny"} {
+		if status, body := doJSON(t, srv, http.MethodGet, "/customer"+query, ""); status != http.StatusBadRequest {
+			t.Errorf("GET /customer%s: status %d, want 400:\n%s", query, status, body)
+		}
+	}
+}
+
+func TestGetCustomersActiveFilter(t *testing.T) {
+	srv := newTestServer(t)
+	active := createTestCustomer(t, srv, "Jane Doe", "jane@example.com")
+	inactive := createTestCustomer(t, srv, "John Doe", "john@example.com")
+	if status, body := doJSON(t, srv, http.MethodPatch, fmt.Sprintf("/customers/%d/status", inactive), `{"active":false}`); status != http.StatusOK {
+		t.Fatalf("deactivate: status %d, want 200:\n%s", status, body)
+	}
+
+	tests := []struct {
+		query string
+		want  []int32
+	}{
+		{"", []int32{active, inactive}},
+		{"?active=all", []int32{active, inactive}},
+		{"?active=true", []int32{active}},
+		{"?active=false", []int32{inactive}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			resp, body := send(t, srv, newRequest(t, srv, http.MethodGet, "/customer"+tt.query, "", ""))
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status %d, want 200:\n%s", resp.StatusCode, body)
+			}
+			var customers []struct {
+				ID       int32
+				IsActive bool `json:"is_active"`
+			}
+			if err := json.Unmarshal(body, &customers); err != nil {
+				t.Fatalf("decode customers: %v", err)
+			}
+			var ids []int32
+			for _, c := range customers {
+				ids = append(ids, c.ID)
+				if c.IsActive != (c.ID == active) {
+					t.Errorf("customer %d has is_active %v", c.ID, c.IsActive)
+				}
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("got customers %v, want %v", ids, tt.want)
+			}
+			if got, want := resp.Header.Get("X-Total-Count"), fmt.Sprint(len(tt.want)); got != want {
+				t.Errorf("X-Total-Count = %q, want %s", got, want)
+			}
+		})
+	}
+
+	if status, body := doJSON(t, srv, http.MethodGet, "/customer?active=maybe", ""); status != http.StatusBadRequest {
+		t.Errorf("?active=maybe: status %d, want 400:\n%s", status, body)
+	}
+}
@@ -0,0 +1,106 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/clock"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/config"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/handler"
+)
+
+// newTestServer mounts every route over a memory store, without the
+// middleware, so responses are exactly what the handlers write
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return newTestServerWith(t, testConfig(), nil)
+}
+
+// testConfig is the smallest configuration the handlers run with
+func testConfig() *config.Config {
+	return &config.Config{DefaultPageSize: 20, MaxPageSize: 100, MaxBatchSize: 1000, DefaultSort: "id"}
+}
+
+// newTestServerWith is newTestServer with cfg, and with wrap, when not nil,
+// around the router
+func newTestServerWith(t *testing.T, cfg *config.Config, wrap func(http.Handler) http.Handler) *httptest.Server {
+	t.Helper()
+	service := customer.NewService(customer.NewMemoryRepository(false), nil, nil, clock.Real{}, nil, 0, customer.NameLimits{})
+	return newTestServerFor(t, service, cfg, wrap)
+}
+
+// newTestServerFor is newTestServerWith over a service the test built itself
+func newTestServerFor(t *testing.T, service *customer.Service, cfg *config.Config, wrap func(http.Handler) http.Handler) *httptest.Server {
+	t.Helper()
+	h := handler.NewHandler(service, cfg)
+	router := handler.NewRouter()
+	for _, route := range h.Routes() {
+		router.Handle(route.Method, route.Path, route.Handler)
+	}
+	var root http.Handler = router
+	if wrap != nil {
+		root = wrap(root)
+	}
+	srv := httptest.NewServer(root)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func doJSON(t *testing.T, srv *httptest.Server, method, path, body string) (int, []byte) {
+	t.Helper()
+	return doRequest(t, srv, method, path, "application/json", body)
+}
+
+func doRequest(t *testing.T, srv *httptest.Server, method, path, contentType, body string) (int, []byte) {
+	t.Helper()
+	resp, respBody := send(t, srv, newRequest(t, srv, method, path, contentType, body))
+	return resp.StatusCode, respBody
+}
+
+func newRequest(t *testing.T, srv *httptest.Server, method, path, contentType, body string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return req
+}
+
+// send runs req against srv and returns the response with its body read
+func send(t *testing.T, srv *httptest.Server, req *http.Request) (*http.Response, []byte) {
+	t.Helper()
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", req.Method, req.URL.Path, err)
+	}
+	defer resp.Body.Close()
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(resp.Body); err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return resp, buf.Bytes()
+}
+
+// createTestCustomer registers a customer through the API and returns its ID
+func createTestCustomer(t *testing.T, srv *httptest.Server, name, email string) int32 {
+	t.Helper()
+	status, body := doJSON(t, srv, http.MethodPost, "/customers",
+		fmt.Sprintf(`{"name":%q,"email":%q,"password":"Very-Long-Passw0rd!xyz"}`, name, email))
+	if status != http.StatusCreated {
+		t.Fatalf("create %s: status %d, want 201:\n%s", email, status, body)
+	}
+	var created struct{ ID int32 }
+	if err := json.Unmarshal(body, &created); err != nil {
+		t.Fatalf("decode created customer: %v", err)
+	}
+	return created.ID
+}
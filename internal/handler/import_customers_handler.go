@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/ctxkeys"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+)
+
+// importColumns are the columns an import may carry, in the order assumed
+// when the file has no header row and ?columns is not given
+var importColumns = []string{"name", "email", "password", "phone", "avatar_url"}
+
+// requiredImportColumns must be present in every import
+var requiredImportColumns = []string{"name", "email", "password"}
+
+// importFileField is the multipart form field holding the uploaded file
+const importFileField = "file"
+
+type importCustomersResponse struct {
+	Imported int                 `json:"imported"`
+	Failed   int                 `json:"failed"`
+	Skipped  int                 `json:"skipped"`
+	Rows     []importRowResponse `json:"rows"`
+}
+
+type importRowResponse struct {
+	Line   int                `json:"line"`
+	Status string             `json:"status"`
+	ID     int32              `json:"id,omitempty"`
+	Error  string             `json:"error,omitempty"`
+	Fields []fieldErrorDetail `json:"fields,omitempty"`
+}
+
+// POST
+func (h *Handler) ImportCustomers(w http.ResponseWriter, r *http.Request) {
+	// 1. Parse the mode: atomic imports every row or none, best_effort
+	// imports each row independently
+	mode := r.URL.Query().Get("mode")
+	if mode != "" && mode != "atomic" && mode != "best_effort" {
+		http.Error(w, "mode must be atomic or best_effort", http.StatusBadRequest)
+		return
+	}
+	header := r.URL.Query().Get("header") != "false"
+	var columns []string
+	if v := r.URL.Query().Get("columns"); v != "" {
+		columns = strings.Split(v, ",")
+	}
+
+	// 2. Read the CSV from the body or from a multipart upload
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+	var body io.Reader
+	switch requestMediaType(r) {
+	case "text/csv":
+		body = r.Body
+	case "multipart/form-data":
+		file, _, err := r.FormFile(importFileField)
+		if err != nil {
+			http.Error(w, "multipart upload must carry the CSV in the "+importFileField+" field", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		body = file
+	default:
+		http.Error(w, "content type must be text/csv or multipart/form-data", http.StatusUnsupportedMediaType)
+		return
+	}
+	rows, err := readImportCSV(body, header, columns)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, fmt.Sprintf("request body must not be larger than %d bytes", maxBytesErr.Limit), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(rows) == 0 {
+		http.Error(w, "no rows to import", http.StatusBadRequest)
+		return
+	}
+	if !h.withinBatchLimit(w, len(rows)) {
+		return
+	}
+
+	// 3. Import and report the outcome of every row by its line number
+	atomic := mode != "best_effort"
+	results, err := h.service.ImportCustomers(r.Context(), rows, atomic)
+	if err != nil {
+		h.serverError(w, r, err, "could not import customers")
+		return
+	}
+	resp := importCustomersResponse{Rows: make([]importRowResponse, len(results))}
+	for i, result := range results {
+		row := importRowResponse{Line: result.Line, Status: "imported"}
+		var invalid *customer.ValidationError
+		switch {
+		case result.Err == nil:
+			row.ID = result.Customer.ID
+			resp.Imported++
+		case errors.Is(result.Err, customer.ErrImportSkipped):
+			row.Status = "skipped"
+			resp.Skipped++
+		case errors.As(result.Err, &invalid):
+			row.Status, row.Error = "failed", "validation_failed"
+			for _, field := range invalid.Fields {
+				row.Fields = append(row.Fields, fieldErrorDetail{Field: field.Field, Message: field.Err.Error()})
+			}
+			resp.Failed++
+		case errors.Is(result.Err, customer.ErrEmailAlreadyExists):
+			row.Status, row.Error = "failed", "email_exists"
+			resp.Failed++
+		case errors.Is(result.Err, customer.ErrNameAlreadyExists):
+			row.Status, row.Error = "failed", "name_exists"
+			resp.Failed++
+		case errors.Is(result.Err, customer.ErrAlreadyExists):
+			row.Status, row.Error = "failed", "conflict"
+			resp.Failed++
+		default:
+			slog.ErrorContext(r.Context(), "import row failed", "error", result.Err, "line", result.Line, "request_id", ctxkeys.RequestID(r.Context()))
+			row.Status, row.Error = "failed", "internal"
+			resp.Failed++
+		}
+		resp.Rows[i] = row
+	}
+
+	switch {
+	case !atomic:
+		h.writeJSON(w, r, http.StatusMultiStatus, resp)
+	case resp.Failed > 0:
+		h.writeJSON(w, r, http.StatusUnprocessableEntity, resp)
+	default:
+		h.writeJSON(w, r, http.StatusCreated, resp)
+	}
+}
+
+// readImportCSV parses the rows of an import. The column order comes from
+// columns when given, then from the header row, and is importColumns
+// otherwise; a header row is skipped either way. Column names are matched
+// case-insensitively.
+func readImportCSV(body io.Reader, header bool, columns []string) ([]customer.ImportRow, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	if header {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, importCSVError(err)
+		}
+		// Spreadsheets often save CSV with a UTF-8 byte order mark
+		record[0] = strings.TrimPrefix(record[0], "\ufeff")
+		if columns == nil {
+			columns = record
+		}
+	}
+	if columns == nil {
+		columns = importColumns
+	}
+	index, err := importColumnIndex(columns)
+	if err != nil {
+		return nil, err
+	}
+	reader.FieldsPerRecord = len(columns)
+
+	field := func(record []string, name string) string {
+		if i, ok := index[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	var rows []customer.ImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, importCSVError(err)
+		}
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, customer.ImportRow{
+			Line: line,
+			RegisterInput: customer.RegisterInput{
+				Name:      field(record, "name"),
+				Email:     field(record, "email"),
+				Password:  record[index["password"]],
+				Phone:     field(record, "phone"),
+				AvatarURL: field(record, "avatar_url"),
+			},
+		})
+	}
+}
+
+// importColumnIndex maps each column name to its position, rejecting
+// unknown and repeated columns and requiring requiredImportColumns
+func importColumnIndex(columns []string) (map[string]int, error) {
+	index := make(map[string]int, len(columns))
+	for i, column := range columns {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if !slices.Contains(importColumns, column) {
+			return nil, fmt.Errorf("unknown column %q, columns must be among %s", column, strings.Join(importColumns, ", "))
+		}
+		if _, ok := index[column]; ok {
+			return nil, fmt.Errorf("column %q appears more than once", column)
+		}
+		index[column] = i
+	}
+	for _, column := range requiredImportColumns {
+		if _, ok := index[column]; !ok {
+			return nil, fmt.Errorf("column %q is required", column)
+		}
+	}
+	return index, nil
+}
+
+// importCSVError keeps the line number of a CSV syntax error and passes
+// body read errors, such as an oversized body, through unchanged
+func importCSVError(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return fmt.Errorf("invalid CSV on line %d: %v", parseErr.Line, parseErr.Err)
+	}
+	return err
+}
@@ -0,0 +1,143 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type importResponse struct {
+	Imported int `json:"imported"`
+	Failed   int `json:"failed"`
+	Skipped  int `json:"skipped"`
+	Rows     []struct {
+		Line   int    `json:"line"`
+		Status string `json:"status"`
+		ID     int32  `json:"id"`
+		Error  string `json:"error"`
+		Fields []struct {
+			Field string `json:"field"`
+		} `json:"fields"`
+	} `json:"rows"`
+}
+
+func importCSV(t *testing.T, query, csv string) (int, importResponse, []byte) {
+	t.Helper()
+	srv := newTestServer(t)
+	status, body := doRequest(t, srv, http.MethodPost, "/customers/import"+query, "text/csv", csv)
+	var resp importResponse
+	if status < 400 || status == http.StatusUnprocessableEntity {
+		if err := json.Unmarshal(body, &resp); err != nil {
+			t.Fatalf("decode import response: %v\n%s", err, body)
+		}
+	}
+	return status, resp, body
+}
+
+func TestImportCustomersColumns(t *testing.T) {
+	const password = "Very-Long-Passw0rd!xyz"
+	tests := []struct {
+		name  string
+		query string
+		csv   string
+	}{
+		{"header row", "",
+			"name,email,password\nJane Doe,jane@example.com," + password + "\n"},
+		{"header row in another order and case", "",
+			"Password, EMAIL,name\n" + password + ",jane@example.com,Jane Doe\n"},
+		{"header row with a byte order mark", "",
+			"\ufeffname,email,password\nJane Doe,jane@example.com," + password + "\n"},
+		{"no header row", "?header=false",
+			"Jane Doe,jane@example.com," + password + ",,\n"},
+		{"columns parameter", "?header=false&columns=email,password,name",
+			"jane@example.com," + password + ",Jane Doe\n"},
+		{"columns parameter overrides the header row", "?columns=email,password,name",
+			"a,b,c\njane@example.com," + password + ",Jane Doe\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp, body := importCSV(t, tt.query, tt.csv)
+			if status != http.StatusCreated {
+				t.Fatalf("status %d, want 201:\n%s", status, body)
+			}
+			if resp.Imported != 1 || len(resp.Rows) != 1 || resp.Rows[0].ID == 0 {
+				t.Fatalf("response %s, want one imported row", body)
+			}
+		})
+	}
+}
+
+func TestImportCustomersRejectsBadColumns(t *testing.T) {
+	tests := []struct {
+		name, csv, wantErr string
+	}{
+		{"unknown column", "name,email,password,age\n", `unknown column "age"`},
+		{"repeated column", "name,email,email,password\n", `column "email" appears more than once`},
+		{"missing required column", "name,email\n", `column "password" is required`},
+		{"wrong number of fields", "name,email,password\nJane,jane@example.com\n", "invalid CSV on line 2"},
+		{"header only", "name,email,password\n", "no rows to import"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _, body := importCSV(t, "", tt.csv)
+			if status != http.StatusBadRequest || !strings.Contains(string(body), tt.wantErr) {
+				t.Fatalf("status %d %q, want 400 mentioning %q", status, body, tt.wantErr)
+			}
+		})
+	}
+}
+
+// importWithBadRows has a valid row on line 2, an invalid email on line 3,
+// a duplicate of line 2's email on line 4 and a valid row on line 5
+const importWithBadRows = "name,email,password\n" +
+	"Jane Doe,jane@example.com,Very-Long-Passw0rd!xyz\n" +
+	"John Doe,not-an-email,Very-Long-Passw0rd!xyz\n" +
+	"Jane Again,jane@example.com,Very-Long-Passw0rd!xyz\n" +
+	"Mary Major,mary@example.com,Very-Long-Passw0rd!xyz\n"
+
+func TestImportCustomersReportsEachLine(t *testing.T) {
+	status, resp, body := importCSV(t, "?mode=best_effort", importWithBadRows)
+	if status != http.StatusMultiStatus {
+		t.Fatalf("status %d, want 207:\n%s", status, body)
+	}
+	want := []struct {
+		line          int
+		status, error string
+	}{
+		{2, "imported", ""},
+		{3, "failed", "validation_failed"},
+		{4, "failed", "email_exists"},
+		{5, "imported", ""},
+	}
+	if len(resp.Rows) != len(want) {
+		t.Fatalf("got %d rows, want %d:\n%s", len(resp.Rows), len(want), body)
+	}
+	for i, w := range want {
+		row := resp.Rows[i]
+		if row.Line != w.line || row.Status != w.status || row.Error != w.error {
+			t.Errorf("row %d = line %d %s %q, want line %d %s %q", i, row.Line, row.Status, row.Error, w.line, w.status, w.error)
+		}
+	}
+	if fields := resp.Rows[1].Fields; len(fields) != 1 || fields[0].Field != "email" {
+		t.Errorf("line 3 fields = %+v, want email", fields)
+	}
+	if resp.Imported != 2 || resp.Failed != 2 || resp.Skipped != 0 {
+		t.Errorf("counts imported %d failed %d skipped %d, want 2, 2, 0", resp.Imported, resp.Failed, resp.Skipped)
+	}
+}
+
+func TestImportCustomersAtomicSkipsEveryRow(t *testing.T) {
+	status, resp, body := importCSV(t, "", importWithBadRows)
+	if status != http.StatusUnprocessableEntity {
+		t.Fatalf("status %d, want 422:\n%s", status, body)
+	}
+	// Validation runs before anything is stored, so only line 3 fails and
+	// every other row is skipped
+	if resp.Imported != 0 || resp.Failed != 1 || resp.Skipped != 3 {
+		t.Errorf("counts imported %d failed %d skipped %d, want 0, 1, 3:\n%s", resp.Imported, resp.Failed, resp.Skipped, body)
+	}
+	if resp.Rows[1].Line != 3 || resp.Rows[1].Status != "failed" {
+		t.Errorf("row for line 3 = %+v, want failed", resp.Rows[1])
+	}
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/handler/dto"
+)
+
+type loginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// POST
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
+	// 1. Decode the JSON request
+	var request loginRequest
+	if !bindJSON(w, r, &request) {
+		return
+	}
+
+	loggedIn, err := h.service.Authenticate(r.Context(), request.Email, request.Password, h.cfg.RequireVerifiedEmail)
+	if err != nil {
+		switch {
+		case errors.Is(err, customer.ErrInvalidCredentials):
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		case errors.Is(err, customer.ErrCustomerInactive),
+			errors.Is(err, customer.ErrEmailNotVerified):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			h.serverError(w, r, err, "could not log in")
+		}
+		return
+	}
+	resp := dto.NewCustomer(loggedIn)
+	h.writeJSON(w, r, http.StatusOK, resp)
+}
@@ -0,0 +1,64 @@
+package handler_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestLoginInactiveCustomer(t *testing.T) {
+	srv := newTestServer(t)
+	id := createTestCustomer(t, srv, "Jane Doe", "jane@example.com")
+	statusPath := fmt.Sprintf("/customers/%d/status", id)
+	login := func() (int, []byte) {
+		return doJSON(t, srv, http.MethodPost, "/customers/login",
+			`{"email":"jane@example.com","password":"Very-Long-Passw0rd!xyz"}`)
+	}
+
+	if status, body := login(); status != http.StatusOK {
+		t.Fatalf("login while active: status %d, want 200:\n%s", status, body)
+	}
+
+	if status, body := doJSON(t, srv, http.MethodPatch, statusPath, `{"active":false}`); status != http.StatusOK {
+		t.Fatalf("deactivate: status %d, want 200:\n%s", status, body)
+	}
+	if status, body := login(); status != http.StatusForbidden {
+		t.Errorf("login while inactive: status %d, want 403:\n%s", status, body)
+	}
+	status, body := doJSON(t, srv, http.MethodPost, "/customers/login",
+		`{"email":"jane@example.com","password":"Wrong-Long-Passw0rd!xyz"}`)
+	if status != http.StatusUnauthorized {
+		t.Errorf("wrong password while inactive: status %d, want 401 so the status is not revealed:\n%s", status, body)
+	}
+
+	if status, body := doJSON(t, srv, http.MethodPatch, statusPath, `{"active":true}`); status != http.StatusOK {
+		t.Fatalf("reactivate: status %d, want 200:\n%s", status, body)
+	}
+	if status, body := login(); status != http.StatusOK {
+		t.Errorf("login after reactivating: status %d, want 200:\n%s", status, body)
+	}
+}
+
+func TestUpdateCustomerStatusErrors(t *testing.T) {
+	srv := newTestServer(t)
+	id := createTestCustomer(t, srv, "Jane Doe", "jane@example.com")
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+	}{
+		{"missing active", fmt.Sprintf("/customers/%d/status", id), `{}`, http.StatusBadRequest},
+		{"active not a boolean", fmt.Sprintf("/customers/%d/status", id), `{"active":"no"}`, http.StatusBadRequest},
+		{"missing customer", "/customers/999999/status", `{"active":false}`, http.StatusNotFound},
+		{"invalid id", "/customers/abc/status", `{"active":false}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, body := doJSON(t, srv, http.MethodPatch, tt.path, tt.body); status != tt.status {
+				t.Errorf("PATCH %s %s: status %d, want %d:\n%s", tt.path, tt.body, status, tt.status, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/handler/dto"
+)
+
+type lookupCustomersByEmailsRequest struct {
+	Emails []string `json:"emails"`
+}
+
+type lookupCustomersByEmailsResponse struct {
+	// Found is keyed by lowercased email
+	Found    map[string]dto.Customer `json:"found"`
+	NotFound []string                `json:"not_found"`
+}
+
+// POST
+func (h *Handler) LookupCustomersByEmails(w http.ResponseWriter, r *http.Request) {
+	// 1. Decode the JSON request
+	var request lookupCustomersByEmailsRequest
+	if !bindJSON(w, r, &request) {
+		return
+	}
+	if !h.withinBatchLimit(w, len(request.Emails)) {
+		return
+	}
+
+	found, missing, err := h.service.LookupCustomersByEmails(r.Context(), request.Emails)
+	if err != nil {
+		switch {
+		case errors.Is(err, customer.ErrNoEmails),
+			errors.Is(err, customer.ErrTooManyEmails),
+			errors.Is(err, customer.ErrInvalidEmail):
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		default:
+			h.serverError(w, r, err, "could not look up customers")
+		}
+		return
+	}
+	resp := lookupCustomersByEmailsResponse{
+		Found:    make(map[string]dto.Customer, len(found)),
+		NotFound: missing,
+	}
+	for email, c := range found {
+		resp.Found[email] = dto.NewCustomer(&c)
+	}
+	if resp.NotFound == nil {
+		resp.NotFound = []string{}
+	}
+	h.writeJSON(w, r, http.StatusOK, resp)
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/handler/dto"
+)
+
+type mergeCustomersRequest struct {
+	KeepID  int32 `json:"keep_id"`
+	MergeID int32 `json:"merge_id"`
+}
+
+// POST
+func (h *Handler) MergeCustomers(w http.ResponseWriter, r *http.Request) {
+	// 1. Decode the JSON request
+	var request mergeCustomersRequest
+	if !bindJSON(w, r, &request) {
+		return
+	}
+	if request.KeepID <= 0 || request.MergeID <= 0 {
+		http.Error(w, "keep_id and merge_id are required", http.StatusBadRequest)
+		return
+	}
+
+	keptCustomer, err := h.service.MergeCustomers(r.Context(), request.KeepID, request.MergeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, customer.ErrMergeSameCustomer):
+			http.Error(w, "keep_id and merge_id must differ", http.StatusBadRequest)
+		case errors.Is(err, customer.ErrCustomerNotFound):
+			h.notFound(w, r, "customer")
+		default:
+			h.serverError(w, r, err, "could not merge customers")
+		}
+		return
+	}
+	resp := dto.NewCustomer(keptCustomer)
+	h.writeJSON(w, r, http.StatusOK, resp)
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// pagination is the 1-based page window requested through ?page and ?page_size
+type pagination struct {
+	Page     int32
+	PageSize int32
+}
+
+func (p pagination) limit() int32  { return p.PageSize }
+func (p pagination) offset() int32 { return (p.Page - 1) * p.PageSize }
+
+// parsePagination reads ?page and ?page_size, falling back to the first page
+// of the configured default page size
+func (h *Handler) parsePagination(query url.Values) (pagination, error) {
+	return h.parsePaginationDefault(query, h.cfg.DefaultPageSize)
+}
+
+// parsePaginationDefault is parsePagination with a different default page
+// size, for views that want smaller pages
+func (h *Handler) parsePaginationDefault(query url.Values, defaultPageSize int) (pagination, error) {
+	p := pagination{Page: 1, PageSize: int32(defaultPageSize)}
+	if v := query.Get("page"); v != "" {
+		page, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || page < 1 {
+			return p, errors.New("page must be a positive integer")
+		}
+		p.Page = int32(page)
+	}
+	if v := query.Get("page_size"); v != "" {
+		size, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || size < 1 || size > int64(h.cfg.MaxPageSize) {
+			return p, fmt.Errorf("page_size must be between 1 and %d", h.cfg.MaxPageSize)
+		}
+		p.PageSize = int32(size)
+	}
+	return p, nil
+}
+
+// setPaginationHeaders sets X-Total-Count and an RFC 5988 Link header with
+// first, prev, next and last URLs relative to the current request, under the
+// configured base path
+func (h *Handler) setPaginationHeaders(w http.ResponseWriter, r *http.Request, p pagination, total int64) {
+	lastPage := int32((total + int64(p.PageSize) - 1) / int64(p.PageSize))
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	links := []string{h.pageLink(r, p, 1, "first")}
+	if p.Page > 1 {
+		links = append(links, h.pageLink(r, p, min(p.Page-1, lastPage), "prev"))
+	}
+	if p.Page < lastPage {
+		links = append(links, h.pageLink(r, p, p.Page+1, "next"))
+	}
+	links = append(links, h.pageLink(r, p, lastPage, "last"))
+
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+	w.Header().Set("Link", strings.Join(links, ", "))
+}
+
+func (h *Handler) pageLink(r *http.Request, p pagination, page int32, rel string) string {
+	query := r.URL.Query()
+	query.Set("page", strconv.Itoa(int(page)))
+	query.Set("page_size", strconv.Itoa(int(p.PageSize)))
+	u := url.URL{Path: h.cfg.BasePath + r.URL.Path, RawQuery: query.Encode()}
+	return fmt.Sprintf(`<%s>; rel="%s"`, u.String(), rel)
+}
@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+var errInvalidID = errors.New("invalid customer id")
+
+// parseIDParam reads a positive int32 ID from the named path parameter.
+// Overflowing, zero, negative and non-numeric values are all errInvalidID, so
+// they are rejected before reaching the database.
+func parseIDParam(r *http.Request, name string) (int32, error) {
+	id, err := strconv.ParseInt(r.PathValue(name), 10, 32)
+	if err != nil || id <= 0 {
+		return 0, errInvalidID
+	}
+	return int32(id), nil
+}
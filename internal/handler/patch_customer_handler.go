@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/handler/dto"
+)
+
+// patchCustomerRequest uses pointers so an omitted field (nil) can be told
+// apart from one explicitly set to ""
+type patchCustomerRequest struct {
+	Name      *string `json:"name"`
+	Email     *string `json:"email"`
+	Password  *string `json:"password"`
+	Phone     *string `json:"phone"`
+	AvatarURL *string `json:"avatar_url"`
+}
+
+// unchangedHeader marks a patch that left every stored value as it was
+const unchangedHeader = "X-Unchanged"
+
+// mergePatchMediaType is the content type of a JSON Merge Patch (RFC 7386)
+const mergePatchMediaType = "application/merge-patch+json"
+
+// mergePatchCustomerRequest is the JSON Merge Patch form of
+// patchCustomerRequest. Members are kept raw so a null, which removes the
+// field, can be told apart from an absent member.
+type mergePatchCustomerRequest struct {
+	Name      json.RawMessage `json:"name"`
+	Email     json.RawMessage `json:"email"`
+	Password  json.RawMessage `json:"password"`
+	Phone     json.RawMessage `json:"phone"`
+	AvatarURL json.RawMessage `json:"avatar_url"`
+}
+
+// patchInput maps the patch onto PatchInput's convention: absent is nil and
+// null is "", which clears an optional field and fails validation of a
+// required one
+func (m mergePatchCustomerRequest) patchInput() (customer.PatchInput, error) {
+	var in customer.PatchInput
+	for _, member := range []struct {
+		name string
+		raw  json.RawMessage
+		dst  **string
+	}{
+		{"name", m.Name, &in.Name},
+		{"email", m.Email, &in.Email},
+		{"password", m.Password, &in.Password},
+		{"phone", m.Phone, &in.Phone},
+		{"avatar_url", m.AvatarURL, &in.AvatarURL},
+	} {
+		if member.raw == nil {
+			continue
+		}
+		value := new(string)
+		if string(member.raw) != "null" {
+			if err := json.Unmarshal(member.raw, value); err != nil {
+				var typeErr *json.UnmarshalTypeError
+				if errors.As(err, &typeErr) {
+					typeErr.Field = member.name
+				}
+				return customer.PatchInput{}, err
+			}
+		}
+		*member.dst = value
+	}
+	return in, nil
+}
+
+// PATCH, as plain JSON or as a JSON Merge Patch
+func (h *Handler) PatchCustomer(w http.ResponseWriter, r *http.Request) {
+	// 1. Parse the customer ID from the path
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "invalid customer id", http.StatusBadRequest)
+		return
+	}
+	// 2. Read the expected version from If-Match
+	if h.cfg.RequireIfMatch && r.Header.Get("If-Match") == "" {
+		http.Error(w, "If-Match header is required", http.StatusPreconditionRequired)
+		return
+	}
+	ifUpdatedAt, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+	// 3. Decode the request in the format the client sent
+	var input customer.PatchInput
+	if requestMediaType(r) == mergePatchMediaType {
+		var request mergePatchCustomerRequest
+		if !bindJSONAs(w, r, &request, mergePatchMediaType) {
+			return
+		}
+		if input, err = request.patchInput(); err != nil {
+			status, msg := decodeErrorResponse(err)
+			http.Error(w, msg, status)
+			return
+		}
+	} else {
+		var request patchCustomerRequest
+		if !bindJSON(w, r, &request) {
+			return
+		}
+		input = customer.PatchInput{
+			Name:      request.Name,
+			Email:     request.Email,
+			Password:  request.Password,
+			Phone:     request.Phone,
+			AvatarURL: request.AvatarURL,
+		}
+	}
+	input.IfUpdatedAt = ifUpdatedAt
+
+	patchedCustomer, changed, err := h.service.PatchCustomer(r.Context(), id, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, customer.ErrCustomerNotFound):
+			h.notFound(w, r, "customer")
+		case errors.Is(err, customer.ErrStaleCustomer):
+			http.Error(w, "customer was modified, fetch it again and retry", http.StatusPreconditionFailed)
+		case errors.Is(err, customer.ErrNameRequired),
+			errors.Is(err, customer.ErrNameInvalidUTF8),
+			errors.Is(err, customer.ErrNameTooLong),
+			errors.Is(err, customer.ErrNameTooManyBytes),
+			errors.Is(err, customer.ErrInvalidEmail),
+			errors.Is(err, customer.ErrDisposableEmail),
+			errors.Is(err, customer.ErrInvalidPhone),
+			errors.Is(err, customer.ErrInvalidAvatarURL),
+			errors.Is(err, customer.ErrWeakPassword),
+			errors.Is(err, customer.ErrPasswordTooLong),
+			errors.Is(err, customer.ErrPasswordContainsIdentity):
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		case errors.Is(err, customer.ErrBreachedPassword):
+			http.Error(w, "password has appeared in a data breach, choose another", http.StatusUnprocessableEntity)
+		case errors.Is(err, customer.ErrEmailAlreadyExists):
+			http.Error(w, "email already exists", http.StatusConflict)
+		case errors.Is(err, customer.ErrNameAlreadyExists):
+			http.Error(w, "name already exists", http.StatusConflict)
+		case errors.Is(err, customer.ErrAlreadyExists):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			h.serverError(w, r, err, "could not update customer")
+		}
+		return
+	}
+	w.Header().Set("ETag", customerETag(patchedCustomer))
+	if !changed {
+		// 304 is only for conditional GET and HEAD, so a no-op patch still
+		// answers 200 and flags that nothing was written
+		w.Header().Set(unchangedHeader, "true")
+	}
+	resp := dto.NewCustomer(patchedCustomer)
+	h.writeJSON(w, r, http.StatusOK, resp)
+}
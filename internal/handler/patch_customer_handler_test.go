@@ -0,0 +1,283 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// assertNoPassword fails when a response carries a password key at any
+// depth, or the bcrypt hash itself
+func assertNoPassword(t *testing.T, body []byte) {
+	t.Helper()
+	var decoded any
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("response is not JSON: %v\n%s", err, body)
+	}
+	if path, ok := findKey(decoded, "password", "$"); ok {
+		t.Errorf("response has a password at %s:\n%s", path, body)
+	}
+	if bytes.Contains(body, []byte("$2a$")) {
+		t.Errorf("response contains a bcrypt hash:\n%s", body)
+	}
+}
+
+func findKey(v any, key, path string) (string, bool) {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			if strings.EqualFold(k, key) {
+				return path + "." + k, true
+			}
+			if found, ok := findKey(child, key, path+"."+k); ok {
+				return found, true
+			}
+		}
+	case []any:
+		for i, child := range v {
+			if found, ok := findKey(child, key, fmt.Sprintf("%s[%d]", path, i)); ok {
+				return found, true
+			}
+		}
+	}
+	return "", false
+}
+
+func TestPatchCustomerNeverReturnsPassword(t *testing.T) {
+	srv := newTestServer(t)
+	status, body := doJSON(t, srv, http.MethodPost, "/customers",
+		`{"name":"Jane Doe","email":"jane@example.com","password":"Very-Long-Passw0rd!xyz"}`)
+	if status != http.StatusCreated {
+		t.Fatalf("create: status %d, want 201:\n%s", status, body)
+	}
+	assertNoPassword(t, body)
+	var created struct{ ID int32 }
+	if err := json.Unmarshal(body, &created); err != nil {
+		t.Fatalf("decode created customer: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{"password only", "application/json", `{"password":"Another-Long-Passw0rd!abc"}`},
+		{"password and name", "application/json", `{"name":"Jane Roe","password":"Third-Long-Passw0rd!def"}`},
+		{"merge patch", "application/merge-patch+json", `{"password":"Fourth-Long-Passw0rd!ghi"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := doRequest(t, srv, http.MethodPatch, fmt.Sprintf("/customers/%d", created.ID), tt.contentType, tt.body)
+			if status != http.StatusOK {
+				t.Fatalf("patch: status %d, want 200:\n%s", status, body)
+			}
+			assertNoPassword(t, body)
+		})
+	}
+
+	status, body = doJSON(t, srv, http.MethodPost, "/customers/login",
+		`{"email":"jane@example.com","password":"Fourth-Long-Passw0rd!ghi"}`)
+	if status != http.StatusOK {
+		t.Fatalf("login with the patched password: status %d, want 200:\n%s", status, body)
+	}
+	assertNoPassword(t, body)
+}
+
+func TestPatchCustomerPasswordTooLong(t *testing.T) {
+	srv := newTestServer(t)
+	id := createTestCustomer(t, srv, "Jane Doe", "jane@example.com")
+
+	status, body := doJSON(t, srv, http.MethodPatch, fmt.Sprintf("/customers/%d", id),
+		fmt.Sprintf(`{"password":%q}`, strings.Repeat("x", 80)))
+	if status != http.StatusUnprocessableEntity {
+		t.Fatalf("80-byte password: status %d, want 422:\n%s", status, body)
+	}
+}
+
+func TestPatchCustomerUnchanged(t *testing.T) {
+	srv := newTestServer(t)
+	id := createTestCustomer(t, srv, "Jane Doe", "jane@example.com")
+	path := fmt.Sprintf("/customers/%d", id)
+
+	resp, body := send(t, srv, newRequest(t, srv, http.MethodPatch, path, "application/json", `{"name":"Jane Doe"}`))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("no-op patch: status %d, want 200:\n%s", resp.StatusCode, body)
+	}
+	if got := resp.Header.Get("X-Unchanged"); got != "true" {
+		t.Errorf("no-op patch: X-Unchanged = %q, want true", got)
+	}
+	if resp.Header.Get("ETag") == "" {
+		t.Error("no-op patch: missing ETag")
+	}
+	var unchanged struct{ ID int32 }
+	if err := json.Unmarshal(body, &unchanged); err != nil || unchanged.ID != id {
+		t.Fatalf("no-op patch: body is not the customer (%v):\n%s", err, body)
+	}
+
+	resp, body = send(t, srv, newRequest(t, srv, http.MethodPatch, path, "application/json", `{"name":"Jane Roe"}`))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("rename: status %d, want 200:\n%s", resp.StatusCode, body)
+	}
+	if got := resp.Header.Get("X-Unchanged"); got != "" {
+		t.Errorf("rename: X-Unchanged = %q, want none", got)
+	}
+}
+
+func TestPatchCustomerIfMatch(t *testing.T) {
+	cfg := testConfig()
+	cfg.RequireIfMatch = true
+	srv := newTestServerWith(t, cfg, nil)
+	id := createTestCustomer(t, srv, "Jane Doe", "jane@example.com")
+	path := fmt.Sprintf("/customers/%d", id)
+
+	resp, body := send(t, srv, newRequest(t, srv, http.MethodGet, path, "", ""))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("get: status %d, want 200:\n%s", resp.StatusCode, body)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("get: missing ETag")
+	}
+
+	patch := func(ifMatch, name string) (*http.Response, []byte) {
+		t.Helper()
+		req := newRequest(t, srv, http.MethodPatch, path, "application/json", fmt.Sprintf(`{"name":%q}`, name))
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		return send(t, srv, req)
+	}
+
+	if resp, body := patch("", "Jane Roe"); resp.StatusCode != http.StatusPreconditionRequired {
+		t.Fatalf("without If-Match: status %d, want 428:\n%s", resp.StatusCode, body)
+	}
+	if resp, body := patch(`"1"`, "Jane Roe"); resp.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("stale If-Match: status %d, want 412:\n%s", resp.StatusCode, body)
+	}
+	if resp, body := patch("not-an-etag", "Jane Roe"); resp.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("malformed If-Match: status %d, want 412:\n%s", resp.StatusCode, body)
+	}
+
+	resp, body = patch(etag, "Jane Roe")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("current If-Match: status %d, want 200:\n%s", resp.StatusCode, body)
+	}
+	if resp.Header.Get("ETag") == etag {
+		t.Fatal("rename kept the old ETag, so the stale case below would not be stale")
+	}
+	if resp, body := patch(etag, "Jane Poe"); resp.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("If-Match read before the rename: status %d, want 412:\n%s", resp.StatusCode, body)
+	}
+}
+
+func TestPatchCustomerIfMatchOptional(t *testing.T) {
+	srv := newTestServer(t)
+	id := createTestCustomer(t, srv, "Jane Doe", "jane@example.com")
+
+	status, body := doJSON(t, srv, http.MethodPatch, fmt.Sprintf("/customers/%d", id), `{"name":"Jane Roe"}`)
+	if status != http.StatusOK {
+		t.Fatalf("without If-Match and REQUIRE_IF_MATCH off: status %d, want 200:\n%s", status, body)
+	}
+}
+
+// patchedCustomer is the part of a customer response the merge patch tests read
+type patchedCustomer struct {
+	Name      string
+	Email     string
+	Phone     *string
+	AvatarURL *string `json:"avatar_url"`
+}
+
+func TestPatchCustomerMergePatch(t *testing.T) {
+	phone, avatar := "+14155550100", "https://example.com/jane.png"
+	original := patchedCustomer{Name: "Jane Doe", Email: "jane@example.com", Phone: &phone, AvatarURL: &avatar}
+	newPhone := "+14155550199"
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		want   patchedCustomer
+	}{
+		{"set", `{"phone":"+14155550199"}`, http.StatusOK,
+			patchedCustomer{Name: "Jane Doe", Email: "jane@example.com", Phone: &newPhone, AvatarURL: &avatar}},
+		{"null clears", `{"phone":null}`, http.StatusOK,
+			patchedCustomer{Name: "Jane Doe", Email: "jane@example.com", AvatarURL: &avatar}},
+		{"set and clear together", `{"name":"Jane Roe","avatar_url":null}`, http.StatusOK,
+			patchedCustomer{Name: "Jane Roe", Email: "jane@example.com", Phone: &phone}},
+		{"absent leaves all", `{}`, http.StatusOK, original},
+		{"null on a required field", `{"name":null}`, http.StatusUnprocessableEntity, original},
+		{"wrong type", `{"phone":42}`, http.StatusBadRequest, original},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t)
+			status, body := doJSON(t, srv, http.MethodPost, "/customers",
+				fmt.Sprintf(`{"name":"Jane Doe","email":"jane@example.com","password":"Very-Long-Passw0rd!xyz","phone":%q,"avatar_url":%q}`, phone, avatar))
+			if status != http.StatusCreated {
+				t.Fatalf("create: status %d, want 201:\n%s", status, body)
+			}
+			var created struct{ ID int32 }
+			if err := json.Unmarshal(body, &created); err != nil {
+				t.Fatalf("decode created customer: %v", err)
+			}
+			path := fmt.Sprintf("/customers/%d", created.ID)
+
+			status, body = doRequest(t, srv, http.MethodPatch, path, "application/merge-patch+json", tt.body)
+			if status != tt.status {
+				t.Fatalf("patch %s: status %d, want %d:\n%s", tt.body, status, tt.status, body)
+			}
+
+			status, body = doJSON(t, srv, http.MethodGet, path, "")
+			if status != http.StatusOK {
+				t.Fatalf("get: status %d, want 200:\n%s", status, body)
+			}
+			var got patchedCustomer
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatalf("decode customer: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("after patch %s:\n got %v\nwant %v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func (c patchedCustomer) String() string {
+	text := func(s *string) string {
+		if s == nil {
+			return "null"
+		}
+		return *s
+	}
+	return fmt.Sprintf("name %s, email %s, phone %s, avatar_url %s", c.Name, c.Email, text(c.Phone), text(c.AvatarURL))
+}
+
+func TestPatchCustomerPlainJSONNullIsOmitted(t *testing.T) {
+	srv := newTestServer(t)
+	status, body := doJSON(t, srv, http.MethodPost, "/customers",
+		`{"name":"Jane Doe","email":"jane@example.com","password":"Very-Long-Passw0rd!xyz","phone":"+14155550100"}`)
+	if status != http.StatusCreated {
+		t.Fatalf("create: status %d, want 201:\n%s", status, body)
+	}
+	var created struct{ ID int32 }
+	if err := json.Unmarshal(body, &created); err != nil {
+		t.Fatalf("decode created customer: %v", err)
+	}
+
+	status, body = doJSON(t, srv, http.MethodPatch, fmt.Sprintf("/customers/%d", created.ID), `{"phone":null}`)
+	if status != http.StatusOK {
+		t.Fatalf("patch: status %d, want 200:\n%s", status, body)
+	}
+	var got patchedCustomer
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("decode customer: %v", err)
+	}
+	if got.Phone == nil || *got.Phone != "+14155550100" {
+		t.Errorf("plain JSON null changed the phone: %v", got)
+	}
+}
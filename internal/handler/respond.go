@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/ctxkeys"
+)
+
+// writeJSON writes v as the JSON response body with the given status. The
+// output is indented when Config.PrettyJSON is set or the request asks for it
+// with ?pretty=true.
+//
+// v is encoded in full before anything is sent, so a value that fails to
+// encode yields a 500 rather than the intended status with a truncated body.
+// Streaming endpoints that cannot buffer, such as the export, log their
+// failures instead.
+func (h *Handler) writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	if h.prettyJSON(r) {
+		encoder.SetIndent("", "  ")
+	}
+	if err := encoder.Encode(v); err != nil {
+		// Not serverError, which answers through writeJSON itself
+		slog.ErrorContext(r.Context(), "could not encode response", "error", err, "request_id", ctxkeys.RequestID(r.Context()))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(body.Len()))
+	w.WriteHeader(status)
+	w.Write(body.Bytes())
+}
+
+func (h *Handler) prettyJSON(r *http.Request) bool {
+	if h.cfg.PrettyJSON {
+		return true
+	}
+	pretty, _ := strconv.ParseBool(r.URL.Query().Get("pretty"))
+	return pretty
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+)
+
+type rotateCustomerTokenResponse struct {
+	ID    int32  `json:"id"`
+	Token string `json:"token"`
+}
+
+// POST
+func (h *Handler) RotateCustomerToken(w http.ResponseWriter, r *http.Request) {
+	// 1. Parse the customer ID from the path
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "invalid customer id", http.StatusBadRequest)
+		return
+	}
+
+	token, err := h.service.RotateAPIToken(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, customer.ErrCustomerNotFound) {
+			h.notFound(w, r, "customer")
+			return
+		}
+		h.serverError(w, r, err, "could not rotate customer token")
+		return
+	}
+	// 2. The plaintext is only ever shown in this response; keep it out of caches
+	w.Header().Set("Cache-Control", "no-store")
+	resp := rotateCustomerTokenResponse{
+		ID:    id,
+		Token: token,
+	}
+	h.writeJSON(w, r, http.StatusOK, resp)
+}
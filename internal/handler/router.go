@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Router dispatches requests like ServeMux, which it wraps, but answers a
+// known path requested with a method it has no route for with 405 and an
+// Allow header listing the methods it does have; unknown paths get 404.
+// The most specific path decides: GET /customers/merge is a 405 when only
+// POST /customers/merge exists, even though GET /customers/{id} would match.
+// Handlers registered through it never need to check r.Method themselves.
+type Router struct {
+	mux *http.ServeMux
+	// paths holds every registered path without its method, so a request whose
+	// method matched nothing can still be traced back to its path
+	paths   *http.ServeMux
+	allowed map[string][]string
+}
+
+func NewRouter() *Router {
+	return &Router{mux: http.NewServeMux(), paths: http.NewServeMux(), allowed: make(map[string][]string)}
+}
+
+// Handle registers handler for method requests on path, a ServeMux path
+// pattern. A GET route also serves HEAD, as with ServeMux. Registering the
+// same method and path twice is a wiring bug, so it panics while the server
+// is starting rather than leaving one of the handlers unreachable.
+func (rt *Router) Handle(method, path string, handler http.Handler) {
+	if slices.Contains(rt.allowed[path], method) {
+		panic(fmt.Sprintf("handler: route %s %s registered twice", method, path))
+	}
+	rt.mux.Handle(method+" "+path, handler)
+	if _, ok := rt.allowed[path]; !ok {
+		rt.paths.Handle(path, http.NotFoundHandler())
+	}
+	rt.allowed[path] = append(rt.allowed[path], method)
+	if method == http.MethodGet {
+		rt.allowed[path] = append(rt.allowed[path], http.MethodHead)
+	}
+	slices.Sort(rt.allowed[path])
+}
+
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// A method-less ServeMux picks the most specific registered path, so a
+	// literal path shadows any wildcard path that also matches the request
+	if _, path := rt.paths.Handler(r); path != "" {
+		if allowed, ok := rt.allowed[path]; ok && !slices.Contains(allowed, r.Method) {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+	}
+	rt.mux.ServeHTTP(w, r)
+}
@@ -0,0 +1,64 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/handler"
+)
+
+func TestRouterPanicsOnDuplicateRoute(t *testing.T) {
+	router := handler.NewRouter()
+	router.Handle(http.MethodGet, "/customers/{id}", http.NotFoundHandler())
+	// Another method on the same path is a separate route
+	router.Handle(http.MethodDelete, "/customers/{id}", http.NotFoundHandler())
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("registering GET /customers/{id} twice did not panic")
+		}
+		if msg, _ := r.(string); !strings.Contains(msg, "GET /customers/{id}") {
+			t.Fatalf("panic %v does not name the route", r)
+		}
+	}()
+	router.Handle(http.MethodGet, "/customers/{id}", http.NotFoundHandler())
+}
+
+func TestRouterMethodNotAllowed(t *testing.T) {
+	router := handler.NewRouter()
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	router.Handle(http.MethodPost, "/customers/merge", ok)
+	router.Handle(http.MethodGet, "/customers/{id}", ok)
+	router.Handle(http.MethodDelete, "/customers/{id}", ok)
+
+	tests := []struct {
+		method, path string
+		wantStatus   int
+		wantAllow    string
+	}{
+		{http.MethodPost, "/customers/merge", http.StatusNoContent, ""},
+		{http.MethodGet, "/customers/merge", http.StatusMethodNotAllowed, "POST"},
+		{http.MethodDelete, "/customers/merge", http.StatusMethodNotAllowed, "POST"},
+		{http.MethodGet, "/customers/7", http.StatusNoContent, ""},
+		{http.MethodHead, "/customers/7", http.StatusNoContent, ""},
+		{http.MethodPut, "/customers/7", http.StatusMethodNotAllowed, "DELETE, GET, HEAD"},
+		{http.MethodGet, "/unknown", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Allow"); got != tt.wantAllow {
+				t.Fatalf("Allow = %q, want %q", got, tt.wantAllow)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/handler/dto"
+)
+
+// Route is one endpoint of the API. The same list mounts the routes on the
+// Router and generates GET /docs, so the documentation cannot drift.
+type Route struct {
+	Method  string
+	Path    string
+	Summary string
+	// Request and Response are zero values of the body types, used only to
+	// describe their shape; nil means the route has no such body
+	Request  any
+	Response any
+	Handler  http.HandlerFunc
+}
+
+// Routes lists every endpoint the handler serves
+func (h *Handler) Routes() []Route {
+	return []Route{
+		{Method: http.MethodPost, Path: "/customers", Summary: "Register a customer",
+			Request: createCustomerRequest{}, Response: createCustomerResponse{}, Handler: h.CreateCustomer},
+		{Method: http.MethodGet, Path: "/customer", Summary: "List customers; supports ?active, ?tag, ?sort, ?page, ?page_size and ?fields. ?view=compact returns only id and name in pages of 10 without X-Total-Count and Link unless ?meta=true",
+			Response: []dto.Customer{}, Handler: h.GetCustomers},
+		{Method: http.MethodPost, Path: "/customers/merge", Summary: "Merge one customer into another",
+			Request: mergeCustomersRequest{}, Response: dto.Customer{}, Handler: h.MergeCustomers},
+		{Method: http.MethodGet, Path: "/customers/export", Summary: "Export every customer as NDJSON, streamed in batches; ?compress=gzip gzips the stream",
+			Response: dto.ExportedCustomer{}, Handler: h.ExportCustomers},
+		{Method: http.MethodPost, Path: "/customers/import", Summary: "Create customers from a CSV body or multipart upload; ?mode=best_effort imports each row independently",
+			Response: importCustomersResponse{}, Handler: h.ImportCustomers},
+		{Method: http.MethodPost, Path: "/customers/bulk-update", Summary: "Set a field on many customers; ?mode=best_effort reports per-item results",
+			Request: bulkUpdateCustomersRequest{}, Response: bulkUpdateResponse{}, Handler: h.BulkUpdateCustomers},
+		{Method: http.MethodGet, Path: "/customers/analytics/signups", Summary: "Count signups per UTC day from ?from to ?to (YYYY-MM-DD, both included); days without signups count zero",
+			Response: []dailySignupsResponse{}, Handler: h.GetCustomerSignups},
+		{Method: http.MethodGet, Path: "/customers/by-email", Summary: "Look up a customer by ?email",
+			Response: dto.Customer{}, Handler: h.GetCustomerByEmail},
+		{Method: http.MethodPost, Path: "/customers/login", Summary: "Check a customer's email and password and record the login",
+			Request: loginRequest{}, Response: dto.Customer{}, Handler: h.Login},
+		{Method: http.MethodGet, Path: "/customers/lookup", Summary: "Look up a customer by ?id_or_email, read as an ID when it is all digits and as an email otherwise",
+			Response: dto.Customer{}, Handler: h.GetCustomerByEmailOrID},
+		{Method: http.MethodPost, Path: "/customers/lookup-by-emails", Summary: "Resolve many emails to customers in one request",
+			Request: lookupCustomersByEmailsRequest{}, Response: lookupCustomersByEmailsResponse{}, Handler: h.LookupCustomersByEmails},
+		{Method: http.MethodGet, Path: "/customers/search", Summary: "Find customers whose name or email contains ?q, exact email matches first; supports ?page and ?page_size",
+			Response: []dto.Customer{}, Handler: h.SearchCustomers},
+		{Method: http.MethodGet, Path: "/customers/stats", Summary: "Aggregate customer counts",
+			Response: customerStatsResponse{}, Handler: h.GetCustomerStats},
+		{Method: http.MethodPost, Path: "/customers/verify-email", Summary: "Mark a customer's email as verified with the token issued to them",
+			Request: verifyEmailRequest{}, Response: dto.Customer{}, Handler: h.VerifyEmail},
+		{Method: http.MethodGet, Path: "/customers/{id}", Summary: "Get a customer; HEAD checks existence only",
+			Response: dto.Customer{}, Handler: h.GetCustomerByID},
+		{Method: http.MethodPatch, Path: "/customers/{id}", Summary: "Update some fields of a customer; honors If-Match and accepts application/merge-patch+json, where null clears a field; a patch that changes nothing answers X-Unchanged: true",
+			Request: patchCustomerRequest{}, Response: dto.Customer{}, Handler: h.PatchCustomer},
+		{Method: http.MethodDelete, Path: "/customers/{id}", Summary: "Delete a customer",
+			Response: deletedCustomerResponse{}, Handler: h.DeleteCustomer},
+		{Method: http.MethodPost, Path: "/customers/{id}/anonymize", Summary: "Erase a customer's personal data, keeping the row as deleted",
+			Response: dto.Customer{}, Handler: h.AnonymizeCustomer},
+		{Method: http.MethodPost, Path: "/customers/{id}/email-verification", Summary: "Issue a new email verification token, replacing any pending one; the token is shown once",
+			Response: emailVerificationResponse{}, Handler: h.IssueEmailVerification},
+		{Method: http.MethodPatch, Path: "/customers/{id}/name", Summary: "Rename a customer",
+			Request: updateCustomerNameRequest{}, Response: dto.Customer{}, Handler: h.UpdateCustomerName},
+		{Method: http.MethodPatch, Path: "/customers/{id}/status", Summary: "Activate or deactivate a customer",
+			Request: updateCustomerStatusRequest{}, Response: dto.Customer{}, Handler: h.UpdateCustomerStatus},
+		{Method: http.MethodPost, Path: "/customers/{id}/tags", Summary: "Add tags to a customer",
+			Request: customerTagsRequest{}, Response: customerTagsResponse{}, Handler: h.AddCustomerTags},
+		{Method: http.MethodDelete, Path: "/customers/{id}/tags", Summary: "Remove tags from a customer",
+			Request: customerTagsRequest{}, Response: customerTagsResponse{}, Handler: h.RemoveCustomerTags},
+		{Method: http.MethodPost, Path: "/customers/{id}/token/rotate", Summary: "Issue a new API token, invalidating the previous one; the token is shown once",
+			Response: rotateCustomerTokenResponse{}, Handler: h.RotateCustomerToken},
+		{Method: http.MethodPost, Path: "/customers/{id}/touch", Summary: "Bump a customer's updated_at, and so its ETag, without changing anything else",
+			Response: touchCustomerResponse{}, Handler: h.TouchCustomer},
+		{Method: http.MethodGet, Path: "/docs", Summary: "This description of the API",
+			Response: docsResponse{}, Handler: h.Docs},
+	}
+}
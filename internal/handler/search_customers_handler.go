@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/handler/dto"
+)
+
+// GET
+func (h *Handler) SearchCustomers(w http.ResponseWriter, r *http.Request) {
+	// 1. Parse the requested page
+	page, err := h.parsePagination(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// 2. Match ?q against name and email
+	customers, total, err := h.service.SearchCustomers(r.Context(), r.URL.Query().Get("q"), page.limit(), page.offset())
+	if err != nil {
+		if errors.Is(err, customer.ErrEmptySearchQuery) {
+			http.Error(w, "q is required", http.StatusBadRequest)
+			return
+		}
+		h.serverError(w, r, err, "could not search customers")
+		return
+	}
+	h.setPaginationHeaders(w, r, page, total)
+
+	// 3. Map domain to response
+	h.writeJSON(w, r, http.StatusOK, dto.NewCustomers(customers))
+}
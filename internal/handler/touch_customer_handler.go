@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/handler/dto"
+)
+
+type touchCustomerResponse struct {
+	ID        int32     `json:"id"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Version   int64     `json:"version"`
+}
+
+// POST
+func (h *Handler) TouchCustomer(w http.ResponseWriter, r *http.Request) {
+	// 1. Parse the customer ID from the path
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "invalid customer id", http.StatusBadRequest)
+		return
+	}
+
+	// 2. Bump updated_at and nothing else
+	updatedAt, err := h.service.TouchCustomer(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, customer.ErrCustomerNotFound) {
+			h.notFound(w, r, "customer")
+			return
+		}
+		h.serverError(w, r, err, "could not touch customer")
+		return
+	}
+
+	// 3. Return the new version, with the ETag a GET would now send
+	w.Header().Set("ETag", updatedAtETag(updatedAt))
+	h.writeJSON(w, r, http.StatusOK, touchCustomerResponse{ID: id, UpdatedAt: updatedAt, Version: dto.Version(updatedAt)})
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/handler/dto"
+)
+
+type updateCustomerNameRequest struct {
+	Name *string `json:"name"`
+}
+
+// PATCH
+func (h *Handler) UpdateCustomerName(w http.ResponseWriter, r *http.Request) {
+	// 1. Parse the customer ID from the path
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "invalid customer id", http.StatusBadRequest)
+		return
+	}
+	// 2. Decode the JSON request
+	var request updateCustomerNameRequest
+	if !bindJSON(w, r, &request) {
+		return
+	}
+	if request.Name == nil {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+
+	updatedCustomer, err := h.service.UpdateCustomerName(r.Context(), id, *request.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, customer.ErrCustomerNotFound):
+			h.notFound(w, r, "customer")
+		case errors.Is(err, customer.ErrNameRequired),
+			errors.Is(err, customer.ErrNameInvalidUTF8),
+			errors.Is(err, customer.ErrNameTooLong),
+			errors.Is(err, customer.ErrNameTooManyBytes):
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		case errors.Is(err, customer.ErrNameAlreadyExists):
+			http.Error(w, "name already exists", http.StatusConflict)
+		default:
+			h.serverError(w, r, err, "could not update customer name")
+		}
+		return
+	}
+	resp := dto.NewCustomer(updatedCustomer)
+	h.writeJSON(w, r, http.StatusOK, resp)
+}
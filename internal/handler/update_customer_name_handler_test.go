@@ -0,0 +1,76 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/clock"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+)
+
+func TestUpdateCustomerName(t *testing.T) {
+	limits := customer.NameLimits{MaxLength: 20, MaxBytes: 30}
+	service := customer.NewService(customer.NewMemoryRepository(false), nil, nil, clock.Real{}, nil, 0, limits)
+	srv := newTestServerFor(t, service, testConfig(), nil)
+	id := createTestCustomer(t, srv, "Jane Doe", "jane@example.com")
+	deleted := createTestCustomer(t, srv, "John Doe", "john@example.com")
+	if status, body := doJSON(t, srv, http.MethodDelete, fmt.Sprintf("/customers/%d", deleted), ""); status/100 != 2 {
+		t.Fatalf("delete: status %d:\n%s", status, body)
+	}
+	path := fmt.Sprintf("/customers/%d/name", id)
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+		// want is the stored name after a successful rename
+		want string
+	}{
+		{"rename", path, `{"name":"Jane Roe"}`, http.StatusOK, "Jane Roe"},
+		{"trims whitespace", path, `{"name":"  Jane Poe  "}`, http.StatusOK, "Jane Poe"},
+		{"composes to NFC", path, `{"name":"Jose\u0301"}`, http.StatusOK, "Jos\u00e9"},
+		{"missing name", path, `{}`, http.StatusBadRequest, ""},
+		{"empty name", path, `{"name":""}`, http.StatusUnprocessableEntity, ""},
+		{"blank name", path, `{"name":"   "}`, http.StatusUnprocessableEntity, ""},
+		{"too many characters", path, fmt.Sprintf(`{"name":%q}`, strings.Repeat("a", 21)), http.StatusUnprocessableEntity, ""},
+		{"too many bytes", path, fmt.Sprintf(`{"name":%q}`, strings.Repeat("\u00e9", 16)), http.StatusUnprocessableEntity, ""},
+		{"malformed body", path, `{"name":`, http.StatusBadRequest, ""},
+		{"missing customer", "/customers/999999/name", `{"name":"Jane Roe"}`, http.StatusNotFound, ""},
+		{"deleted customer", fmt.Sprintf("/customers/%d/name", deleted), `{"name":"Jane Roe"}`, http.StatusNotFound, ""},
+		{"invalid id", "/customers/abc/name", `{"name":"Jane Roe"}`, http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := doJSON(t, srv, http.MethodPatch, tt.path, tt.body)
+			if status != tt.status {
+				t.Fatalf("PATCH %s %s: status %d, want %d:\n%s", tt.path, tt.body, status, tt.status, body)
+			}
+			if tt.want == "" {
+				return
+			}
+			var renamed struct{ Name, Email string }
+			if err := json.Unmarshal(body, &renamed); err != nil {
+				t.Fatalf("decode customer: %v", err)
+			}
+			if renamed.Name != tt.want || renamed.Email != "jane@example.com" {
+				t.Errorf("renamed to %q with email %q, want %q with the email unchanged", renamed.Name, renamed.Email, tt.want)
+			}
+		})
+	}
+
+	status, body := doJSON(t, srv, http.MethodGet, fmt.Sprintf("/customers/%d", id), "")
+	if status != http.StatusOK {
+		t.Fatalf("get: status %d, want 200:\n%s", status, body)
+	}
+	var stored struct{ Name string }
+	if err := json.Unmarshal(body, &stored); err != nil {
+		t.Fatalf("decode customer: %v", err)
+	}
+	if stored.Name != "Jos\u00e9" {
+		t.Errorf("stored name = %q after the rejected renames, want the last valid one", stored.Name)
+	}
+}
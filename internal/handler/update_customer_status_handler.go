@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/handler/dto"
+)
+
+type updateCustomerStatusRequest struct {
+	Active *bool `json:"active"`
+}
+
+// PATCH
+func (h *Handler) UpdateCustomerStatus(w http.ResponseWriter, r *http.Request) {
+	// 1. Parse the customer ID from the path
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "invalid customer id", http.StatusBadRequest)
+		return
+	}
+	// 2. Decode the JSON request
+	var request updateCustomerStatusRequest
+	if !bindJSON(w, r, &request) {
+		return
+	}
+	if request.Active == nil {
+		http.Error(w, "active is required", http.StatusBadRequest)
+		return
+	}
+
+	updatedCustomer, err := h.service.SetCustomerActive(r.Context(), id, *request.Active)
+	if err != nil {
+		if errors.Is(err, customer.ErrCustomerNotFound) {
+			h.notFound(w, r, "customer")
+			return
+		}
+		h.serverError(w, r, err, "could not update customer status")
+		return
+	}
+	resp := dto.NewCustomer(updatedCustomer)
+	h.writeJSON(w, r, http.StatusOK, resp)
+}
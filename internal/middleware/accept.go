@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// producedTypes are the media types this API can respond with
+var producedTypes = []string{"application/json", "application/x-ndjson"}
+
+// RequireJSONAccept answers 406 when the Accept header rules out every media
+// type the API produces. A missing Accept header accepts anything.
+func RequireJSONAccept(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept := r.Header.Values("Accept")
+		if len(accept) == 0 || acceptsProducedType(strings.Join(accept, ",")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		http.Error(w, "not acceptable: this API only produces application/json", http.StatusNotAcceptable)
+	})
+}
+
+func acceptsProducedType(accept string) bool {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+		// q=0 explicitly marks a type as not acceptable
+		if q := params["q"]; q == "0" || q == "0.0" || q == "0.00" || q == "0.000" {
+			continue
+		}
+		if mediaType == "*/*" || mediaType == "application/*" {
+			return true
+		}
+		for _, produced := range producedTypes {
+			if mediaType == produced {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// StripBasePath removes basePath from the start of the request path so routes
+// registered without it still match. Requests whose path already lacks the
+// prefix, because the proxy stripped it, pass through unchanged.
+func StripBasePath(basePath string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rest, ok := strings.CutPrefix(r.URL.Path, basePath)
+			if !ok || (rest != "" && rest[0] != '/') {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if rest == "" {
+				rest = "/"
+			}
+			r2 := r.Clone(r.Context())
+			r2.URL.Path = rest
+			r2.URL.RawPath = ""
+			next.ServeHTTP(w, r2)
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+)
+
+// ConcurrencyLimit answers 503 with Retry-After once max requests are already
+// in flight, shedding load before it reaches the database pool. The slot is
+// released when the handler returns, including when it panics.
+func ConcurrencyLimit(max int) func(http.Handler) http.Handler {
+	slots := make(chan struct{}, max)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case slots <- struct{}{}:
+			default:
+				RetryAfter(w, time.Second)
+				http.Error(w, "server is busy, retry later", http.StatusServiceUnavailable)
+				return
+			}
+			defer func() { <-slots }()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
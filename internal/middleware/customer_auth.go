@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/ctxkeys"
+)
+
+var (
+	// ErrInvalidToken is what a CustomerAuthenticator returns for a token
+	// that belongs to no customer
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrCustomerForbidden is what a CustomerAuthenticator returns for a
+	// valid token whose customer may not use the API, such as one who has
+	// been deactivated
+	ErrCustomerForbidden = errors.New("customer may not use the API")
+)
+
+// CustomerAuthenticator resolves an API token to the customer it was issued
+// to. It returns ErrInvalidToken or ErrCustomerForbidden for tokens it
+// refuses; any other error is a failure to find out.
+type CustomerAuthenticator func(ctx context.Context, token string) (int32, error)
+
+// AuthenticateCustomer stores the customer a request's "Authorization: Bearer
+// token" header names in ctxkeys.CustomerID. Requests without a bearer token
+// pass through anonymously; a token that names no customer is refused with
+// 401 rather than silently downgraded, so a client with a revoked token finds
+// out, and a forbidden customer's token with 403. It must run before
+// RateLimit, which keys authenticated requests by customer.
+func AuthenticateCustomer(authenticate CustomerAuthenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx := r.Context()
+			id, err := authenticate(ctx, token)
+			switch {
+			case errors.Is(err, ErrInvalidToken):
+				w.Header().Set("WWW-Authenticate", `Bearer realm="customers", error="invalid_token"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+			case errors.Is(err, ErrCustomerForbidden):
+				http.Error(w, "forbidden", http.StatusForbidden)
+			case err != nil:
+				slog.WarnContext(ctx, "could not authenticate customer", "error", err, "request_id", ctxkeys.RequestID(ctx))
+				RetryAfter(w, time.Second)
+				http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+			default:
+				next.ServeHTTP(w, r.WithContext(ctxkeys.WithCustomerID(ctx, id)))
+			}
+		})
+	}
+}
@@ -1 +1,49 @@
 package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/ctxkeys"
+)
+
+// RealIP stores the client IP in the request context for ctxkeys.ClientIP. When
+// trustProxyHeaders is set the IP comes from X-Forwarded-For or X-Real-IP,
+// otherwise from RemoteAddr
+func RealIP(trustProxyHeaders bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := remoteIP(r)
+			if trustProxyHeaders {
+				if forwarded := forwardedIP(r); forwarded != "" {
+					ip = forwarded
+				}
+			}
+			next.ServeHTTP(w, r.WithContext(ctxkeys.WithClientIP(r.Context(), ip)))
+		})
+	}
+}
+
+// forwardedIP takes the right-most X-Forwarded-For entry, the one appended by
+// our own proxy; entries further left are client supplied and can be spoofed
+func forwardedIP(r *http.Request) string {
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(xff[len(xff)-1], ",")
+		if ip := net.ParseIP(strings.TrimSpace(hops[len(hops)-1])); ip != nil {
+			return ip.String()
+		}
+	}
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/ctxkeys"
+)
+
+// RateLimit gives every client a token bucket of burst requests refilled at
+// rate per second, answering 429 with Retry-After once it is empty. Clients
+// are told apart by the authenticated customer ID in ctxkeys.CustomerID, so
+// one account shares a bucket across every address it calls from, and by
+// ctxkeys.ClientIP on requests no customer is authenticated for. It must
+// therefore run after RealIP and AuthenticateCustomer.
+func RateLimit(rate float64, burst int) func(http.Handler) http.Handler {
+	limiter := newRateLimiter(rate, burst)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if wait, ok := limiter.allow(rateLimitKey(r), time.Now()); !ok {
+				RetryAfter(w, wait)
+				http.Error(w, "too many requests, retry later", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitKey names the bucket a request draws from
+func rateLimitKey(r *http.Request) string {
+	if id, ok := ctxkeys.CustomerID(r.Context()); ok {
+		return "customer:" + strconv.FormatInt(int64(id), 10)
+	}
+	return "ip:" + ctxkeys.ClientIP(r.Context())
+}
+
+type rateLimiter struct {
+	rate  float64
+	burst float64
+	// idleTTL is how long a bucket takes to refill completely; one left
+	// alone that long is the same as a new one, so it is dropped
+	idleTTL time.Duration
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		idleTTL: time.Duration(float64(burst) / rate * float64(time.Second)),
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// allow takes a token from key's bucket, or reports how long until one is
+// available
+func (l *rateLimiter) allow(key string, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / l.rate * float64(time.Second)), false
+	}
+	b.tokens--
+	return 0, true
+}
+
+// sweep drops full buckets at most once per idleTTL, so memory follows the
+// number of recently active clients
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.idleTTL {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= l.idleTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
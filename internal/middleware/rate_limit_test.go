@@ -0,0 +1,121 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/ctxkeys"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/middleware"
+)
+
+// testTokens maps the API tokens the tests use to their customers; "carol"
+// is deactivated and "broken" stands for a lookup that fails
+var testTokens = map[string]int32{"alice": 1, "bob": 2}
+
+func authenticateTestToken(ctx context.Context, token string) (int32, error) {
+	switch token {
+	case "broken":
+		return 0, errors.New("database down")
+	case "carol":
+		return 0, middleware.ErrCustomerForbidden
+	}
+	id, ok := testTokens[token]
+	if !ok {
+		return 0, middleware.ErrInvalidToken
+	}
+	return id, nil
+}
+
+// newLimited chains the middleware the way the server does: RealIP, then
+// AuthenticateCustomer, then RateLimit
+func newLimited(burst int, next http.Handler) http.Handler {
+	h := middleware.RateLimit(0.001, burst)(next)
+	h = middleware.AuthenticateCustomer(authenticateTestToken)(h)
+	return middleware.RealIP(false)(h)
+}
+
+func send(h http.Handler, remoteAddr, token string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/customer", nil)
+	r.RemoteAddr = remoteAddr
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	return rec
+}
+
+func TestRateLimitSharesBucketAcrossIPs(t *testing.T) {
+	h := newLimited(2, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, addr := range []string{"192.0.2.1:1000", "198.51.100.1:1000"} {
+		if rec := send(h, addr, "alice"); rec.Code != http.StatusOK {
+			t.Fatalf("alice from %s: status %d, want 200", addr, rec.Code)
+		}
+	}
+	rec := send(h, "203.0.113.1:1000", "alice")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("alice's third request from a new IP: status %d, want 429", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("429 without Retry-After")
+	}
+
+	// Other customers and anonymous requests from the same addresses have
+	// buckets of their own
+	if rec := send(h, "203.0.113.1:1000", "bob"); rec.Code != http.StatusOK {
+		t.Errorf("bob: status %d, want 200", rec.Code)
+	}
+	if rec := send(h, "203.0.113.1:1000", ""); rec.Code != http.StatusOK {
+		t.Errorf("anonymous: status %d, want 200", rec.Code)
+	}
+}
+
+func TestAuthenticateCustomer(t *testing.T) {
+	var gotID int32
+	var gotOK bool
+	h := middleware.AuthenticateCustomer(authenticateTestToken)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotID, gotOK = ctxkeys.CustomerID(r.Context())
+	}))
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantID        int32
+		wantOK        bool
+	}{
+		{"no header", "", http.StatusOK, 0, false},
+		{"not a bearer token", "Basic YWxpY2U6c2VjcmV0", http.StatusOK, 0, false},
+		{"valid token", "Bearer alice", http.StatusOK, 1, true},
+		{"unknown token", "Bearer mallory", http.StatusUnauthorized, 0, false},
+		{"deactivated customer", "Bearer carol", http.StatusForbidden, 0, false},
+		{"failed lookup", "Bearer broken", http.StatusServiceUnavailable, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotID, gotOK = 0, false
+			r := httptest.NewRequest(http.MethodGet, "/customer", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if gotID != tt.wantID || gotOK != tt.wantOK {
+				t.Errorf("CustomerID = %d, %v, want %d, %v", gotID, gotOK, tt.wantID, tt.wantOK)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 without WWW-Authenticate")
+			}
+			if tt.wantStatus == http.StatusServiceUnavailable && rec.Header().Get("Retry-After") == "" {
+				t.Error("503 without Retry-After")
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+)
+
+// ReadOnly rejects every request that could mutate data with 503, letting
+// reads through; useful during database maintenance or replica failover.
+// Maintenance outlasts any single request, so clients are told to wait a
+// while before retrying.
+func ReadOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+		default:
+			RetryAfter(w, 30*time.Second)
+			http.Error(w, "read-only mode", http.StatusServiceUnavailable)
+		}
+	})
+}
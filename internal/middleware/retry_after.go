@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryAfter sets the Retry-After header to d in whole seconds, rounded up
+// and at least one. Every 503 and 429 the API answers goes through it, here
+// and in the handler package, so clients always see delta-seconds rather
+// than an HTTP-date; only the /readyz probe, read by load balancers rather
+// than clients, answers 503 without it.
+func RetryAfter(w http.ResponseWriter, d time.Duration) {
+	seconds := max(int64((d+time.Second-1)/time.Second), 1)
+	w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+}
@@ -0,0 +1,57 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/middleware"
+)
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "1"},
+		{-time.Second, "1"},
+		{time.Millisecond, "1"},
+		{time.Second, "1"},
+		{1500 * time.Millisecond, "2"},
+		{5 * time.Second, "5"},
+		{2 * time.Minute, "120"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.d.String(), func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			middleware.RetryAfter(rec, tt.d)
+			if got := rec.Header().Get("Retry-After"); got != tt.want {
+				t.Fatalf("Retry-After = %q, want %q", got, tt.want)
+			}
+			// An HTTP-date would parse; delta-seconds must not
+			if _, err := http.ParseTime(rec.Header().Get("Retry-After")); err == nil {
+				t.Fatal("Retry-After is an HTTP-date, want delta-seconds")
+			}
+		})
+	}
+}
+
+func TestReadOnlySetsRetryAfter(t *testing.T) {
+	h := middleware.ReadOnly(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/customers", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "30" {
+		t.Fatalf("Retry-After = %q, want %q", got, "30")
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/customers/1", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Retry-After") != "" {
+		t.Fatalf("GET got %d with Retry-After %q, want 200 without it", rec.Code, rec.Header().Get("Retry-After"))
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ShutdownGuard rejects new requests with 503 once shutdown has begun, so
+// clients retry elsewhere instead of racing the database pool being closed
+type ShutdownGuard struct {
+	closing atomic.Bool
+}
+
+// Begin flips the guard; every request after this is rejected
+func (g *ShutdownGuard) Begin() {
+	g.closing.Store(true)
+}
+
+// Wrap returns next guarded by g
+func (g *ShutdownGuard) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if g.closing.Load() {
+			w.Header().Set("Connection", "close")
+			RetryAfter(w, 5*time.Second)
+			http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
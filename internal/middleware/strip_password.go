@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// StripPassword removes every "password" key, matched case-insensitively and
+// at any depth, from application/json responses before they are sent. It is
+// a safety net for handlers that encode a customer model directly; other
+// content types, such as the NDJSON export, stream through untouched.
+func StripPassword(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf := &jsonBuffer{ResponseWriter: w}
+		next.ServeHTTP(buf, r)
+		if !buf.buffering {
+			return
+		}
+
+		body := stripPasswordKeys(buf.body.Bytes())
+		if buf.Header().Get("Content-Length") != "" {
+			buf.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		}
+		w.WriteHeader(buf.status)
+		w.Write(body)
+	})
+}
+
+// jsonBuffer holds back JSON responses so they can be rewritten once the
+// handler returns and passes any other response straight through
+type jsonBuffer struct {
+	http.ResponseWriter
+	decided   bool
+	buffering bool
+	status    int
+	body      bytes.Buffer
+}
+
+func (b *jsonBuffer) decide(status int) {
+	if b.decided {
+		return
+	}
+	b.decided = true
+	b.status = status
+	mediaType, _, _ := mime.ParseMediaType(b.Header().Get("Content-Type"))
+	b.buffering = mediaType == "application/json"
+	if !b.buffering {
+		b.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (b *jsonBuffer) WriteHeader(status int) {
+	b.decide(status)
+}
+
+func (b *jsonBuffer) Write(p []byte) (int, error) {
+	b.decide(http.StatusOK)
+	if b.buffering {
+		return b.body.Write(p)
+	}
+	return b.ResponseWriter.Write(p)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (b *jsonBuffer) Unwrap() http.ResponseWriter {
+	return b.ResponseWriter
+}
+
+// stripPasswordKeys returns body without password keys. Bodies that hold no
+// such key, or are not valid JSON, are returned unchanged byte for byte.
+func stripPasswordKeys(body []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return body
+	}
+	if !removePasswordKeys(v) {
+		return body
+	}
+	stripped, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return append(stripped, '\n')
+}
+
+// removePasswordKeys deletes password keys from v in place and reports
+// whether any were found
+func removePasswordKeys(v any) bool {
+	removed := false
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if strings.EqualFold(key, "password") {
+				delete(v, key)
+				removed = true
+				continue
+			}
+			if removePasswordKeys(value) {
+				removed = true
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if removePasswordKeys(item) {
+				removed = true
+			}
+		}
+	}
+	return removed
+}
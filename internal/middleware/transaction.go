@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/ctxkeys"
+	"github.com/jackc/pgx/v5"
+)
+
+// TxBeginner starts the transaction a request runs in
+type TxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Transaction runs every POST, PUT, PATCH and DELETE request in one database
+// transaction, stored in the request context for the repository to pick up,
+// so a handler that writes several times is atomic without calling RunInTx.
+// The transaction commits when the handler answers 2xx and rolls back on any
+// other status or a panic. The response is held back until the commit
+// succeeds, so a failed commit can still become a 500, and so is the work
+// queued in ctxkeys.AfterCommit, which is dropped on rollback. The work
+// queued in ctxkeys.AfterRollback runs instead whenever the transaction does
+// not commit.
+func Transaction(db TxBeginner) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			tx, err := db.Begin(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "could not begin request transaction", "error", err, "request_id", ctxkeys.RequestID(ctx))
+				RetryAfter(w, time.Second)
+				http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			// Rolling back after a commit is a no-op. WithoutCancel lets the
+			// rollback run even when the request was canceled.
+			defer tx.Rollback(context.WithoutCancel(ctx))
+
+			var afterCommit, afterRollback []func()
+			committed := false
+			defer func() {
+				if !committed {
+					for _, fn := range afterRollback {
+						fn()
+					}
+				}
+			}()
+
+			buf := &txResponse{header: make(http.Header)}
+			txCtx := ctxkeys.WithAfterRollback(ctxkeys.WithAfterCommit(ctxkeys.WithTx(ctx, tx), &afterCommit), &afterRollback)
+			next.ServeHTTP(buf, r.WithContext(txCtx))
+			if buf.status == 0 {
+				buf.status = http.StatusOK
+			}
+			if buf.status >= 200 && buf.status < 300 {
+				if err := tx.Commit(ctx); err != nil {
+					slog.ErrorContext(ctx, "could not commit request transaction", "error", err, "request_id", ctxkeys.RequestID(ctx))
+					http.Error(w, "could not save changes", http.StatusInternalServerError)
+					return
+				}
+				committed = true
+				for _, fn := range afterCommit {
+					fn()
+				}
+			}
+
+			for key, values := range buf.header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(buf.status)
+			w.Write(buf.body.Bytes())
+		})
+	}
+}
+
+// txResponse holds back a whole response until its transaction is settled
+type txResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *txResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *txResponse) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *txResponse) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(p)
+}
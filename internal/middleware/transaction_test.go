@@ -0,0 +1,58 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/ctxkeys"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/middleware"
+	"github.com/jackc/pgx/v5"
+)
+
+// fakeTx is a pgx.Tx whose commit succeeds or fails on demand; the embedded
+// interface is nil, as the middleware only commits and rolls back
+type fakeTx struct {
+	pgx.Tx
+	commitErr error
+}
+
+func (tx fakeTx) Commit(ctx context.Context) error   { return tx.commitErr }
+func (tx fakeTx) Rollback(ctx context.Context) error { return nil }
+
+type fakeBeginner struct{ commitErr error }
+
+func (b fakeBeginner) Begin(ctx context.Context) (pgx.Tx, error) {
+	return fakeTx{commitErr: b.commitErr}, nil
+}
+
+func TestTransactionQueues(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		commitErr error
+		wantRan   []string
+	}{
+		{"committed", http.StatusCreated, nil, []string{"commit"}},
+		{"handler failed", http.StatusConflict, nil, []string{"rollback"}},
+		{"commit failed", http.StatusCreated, errors.New("connection reset"), []string{"rollback"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ran []string
+			h := middleware.Transaction(fakeBeginner{commitErr: tt.commitErr})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				afterCommit, afterRollback := ctxkeys.AfterCommit(r.Context()), ctxkeys.AfterRollback(r.Context())
+				*afterCommit = append(*afterCommit, func() { ran = append(ran, "commit") })
+				*afterRollback = append(*afterRollback, func() { ran = append(ran, "rollback") })
+				w.WriteHeader(tt.status)
+			}))
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/customers", nil))
+			if !slices.Equal(ran, tt.wantRan) {
+				t.Errorf("ran %v, want %v", ran, tt.wantRan)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package pwned
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	rangeURL       = "https://api.pwnedpasswords.com/range/"
+	requestTimeout = 2 * time.Second
+)
+
+// Client checks passwords against the Pwned Passwords range API. Only the
+// first five hex characters of the password's SHA-1 hash leave the process.
+type Client struct {
+	httpClient *http.Client
+}
+
+// NewClient is the constructor for Client
+func NewClient() *Client {
+	return &Client{httpClient: &http.Client{Timeout: requestTimeout}}
+}
+
+// IsBreached reports whether password appears in a known data breach
+func (c *Client) IsBreached(ctx context.Context, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rangeURL+prefix, nil)
+	if err != nil {
+		return false, fmt.Errorf("build pwned passwords request: %w", err)
+	}
+	// Padding hides the real size of the response from observers
+	req.Header.Set("Add-Padding", "true")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("query pwned passwords: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("query pwned passwords: unexpected status %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		candidate, count, ok := strings.Cut(scanner.Text(), ":")
+		// Padding entries always have a count of zero
+		if ok && candidate == suffix && strings.TrimSpace(count) != "0" {
+			return true, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("read pwned passwords response: %w", err)
+	}
+	return false, nil
+}
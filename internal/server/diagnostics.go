@@ -0,0 +1,78 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Diagnostics reports runtime and connection pool figures, enough to spot a
+// goroutine or memory leak, or an exhausted pool, without attaching pprof
+type Diagnostics struct {
+	// pool is nil with the memory storage backend
+	pool *pgxpool.Pool
+}
+
+func NewDiagnostics(pool *pgxpool.Pool) *Diagnostics {
+	return &Diagnostics{pool: pool}
+}
+
+type diagnosticsResponse struct {
+	Goroutines int         `json:"goroutines"`
+	Memory     memoryStats `json:"memory"`
+	Pool       *poolStats  `json:"pool"`
+}
+
+type memoryStats struct {
+	AllocBytes      uint64 `json:"alloc_bytes"`
+	TotalAllocBytes uint64 `json:"total_alloc_bytes"`
+	SysBytes        uint64 `json:"sys_bytes"`
+	HeapObjects     uint64 `json:"heap_objects"`
+	NumGC           uint32 `json:"num_gc"`
+	PauseTotalNs    uint64 `json:"pause_total_ns"`
+}
+
+type poolStats struct {
+	TotalConns           int32 `json:"total_conns"`
+	IdleConns            int32 `json:"idle_conns"`
+	AcquiredConns        int32 `json:"acquired_conns"`
+	MaxConns             int32 `json:"max_conns"`
+	AcquireCount         int64 `json:"acquire_count"`
+	EmptyAcquireCount    int64 `json:"empty_acquire_count"`
+	CanceledAcquireCount int64 `json:"canceled_acquire_count"`
+}
+
+// Stats answers with the current figures. Reading memory stats briefly stops
+// the world, which is why it sits behind the admin token.
+func (d *Diagnostics) Stats(w http.ResponseWriter, r *http.Request) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	resp := diagnosticsResponse{
+		Goroutines: runtime.NumGoroutine(),
+		Memory: memoryStats{
+			AllocBytes:      mem.Alloc,
+			TotalAllocBytes: mem.TotalAlloc,
+			SysBytes:        mem.Sys,
+			HeapObjects:     mem.HeapObjects,
+			NumGC:           mem.NumGC,
+			PauseTotalNs:    mem.PauseTotalNs,
+		},
+	}
+	if d.pool != nil {
+		stat := d.pool.Stat()
+		resp.Pool = &poolStats{
+			TotalConns:           stat.TotalConns(),
+			IdleConns:            stat.IdleConns(),
+			AcquiredConns:        stat.AcquiredConns(),
+			MaxConns:             stat.MaxConns(),
+			AcquireCount:         stat.AcquireCount(),
+			EmptyAcquireCount:    stat.EmptyAcquireCount(),
+			CanceledAcquireCount: stat.CanceledAcquireCount(),
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(resp)
+}
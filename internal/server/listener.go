@@ -0,0 +1,38 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+)
+
+// Listen opens the listener the server accepts connections on: a TCP
+// address such as ":8080", or for network "unix" the path of a socket. A
+// socket file left behind by a previous run that did not shut down cleanly
+// is removed first; any other file at the path is an error rather than
+// something to delete. The socket file is removed again when the listener
+// is closed, which Server.Shutdown does.
+func Listen(network, addr string) (net.Listener, error) {
+	if network == "unix" {
+		if err := removeStaleSocket(addr); err != nil {
+			return nil, err
+		}
+	}
+	return net.Listen(network, addr)
+}
+
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("listen: %s exists and is not a socket", path)
+	}
+	return os.Remove(path)
+}
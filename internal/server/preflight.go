@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/config"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/database"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Preflight runs every check the server needs to pass before serving traffic
+// and reports all failures together, so operators can fix them in one go.
+// pool is nil with the memory storage backend, which skips the database checks.
+func Preflight(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool) error {
+	var errs []error
+
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := customer.ParseListSort(cfg.DefaultSort); err != nil {
+		errs = append(errs, fmt.Errorf("config: DEFAULT_SORT: %w", err))
+	}
+
+	if pool == nil {
+		return errors.Join(errs...)
+	}
+	if err := pool.Ping(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("database unreachable: %w", err))
+	} else {
+		if err := database.CheckSchema(ctx, pool); err != nil {
+			errs = append(errs, err)
+		}
+		if cfg.RequireUniqueName {
+			if err := database.CheckUniqueNameIndex(ctx, pool); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"log"
+	"net/http"
+	"sync/atomic"
+)
+
+// Readiness reports whether this instance should receive new traffic.
+// Draining makes GET /readyz fail so a load balancer stops routing here,
+// while requests that still arrive are served as usual. Once traffic has
+// moved away the process can be stopped without dropping requests.
+type Readiness struct {
+	draining atomic.Bool
+}
+
+// Ready answers 200 while the instance accepts traffic and 503 while it is
+// draining
+func (rd *Readiness) Ready(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	if rd.draining.Load() {
+		http.Error(w, "draining", http.StatusServiceUnavailable)
+		return
+	}
+	w.Write([]byte("ready\n"))
+}
+
+// Drain starts failing the readiness check
+func (rd *Readiness) Drain(w http.ResponseWriter, r *http.Request) {
+	if !rd.draining.Swap(true) {
+		log.Println("Draining: /readyz now reports unavailable")
+	}
+	w.Write([]byte("draining\n"))
+}
+
+// Undrain makes the readiness check pass again
+func (rd *Readiness) Undrain(w http.ResponseWriter, r *http.Request) {
+	if rd.draining.Swap(false) {
+		log.Println("Undrained: /readyz reports ready again")
+	}
+	w.Write([]byte("ready\n"))
+}
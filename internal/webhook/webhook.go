@@ -0,0 +1,169 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Amir-Golmoradi/Customer-Management-System/internal/customer"
+	"github.com/cenkalti/backoff"
+)
+
+const (
+	queueSize      = 256
+	requestTimeout = 5 * time.Second
+	maxRetries     = 5
+	// SignatureHeader carries "sha256=" followed by the hex HMAC-SHA256 of
+	// the request body, keyed with the shared secret
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+)
+
+// payload is the JSON body POSTed for each event
+type payload struct {
+	Type       customer.EventType `json:"type"`
+	CustomerID int32              `json:"customer_id"`
+	OccurredAt time.Time          `json:"occurred_at"`
+}
+
+// Dispatcher delivers customer events to a webhook URL from a background
+// worker, so publishing never blocks the request that caused the event.
+// Events that cannot be delivered after retrying are logged and dropped.
+type Dispatcher struct {
+	url        string
+	secret     []byte
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	closed bool
+	queue  chan customer.Event
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewDispatcher is the constructor for Dispatcher; call Start before
+// publishing and Stop on shutdown
+func NewDispatcher(url, secret string) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Dispatcher{
+		url:        url,
+		secret:     []byte(secret),
+		httpClient: &http.Client{Timeout: requestTimeout},
+		queue:      make(chan customer.Event, queueSize),
+		ctx:        ctx,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+}
+
+// Start runs the delivery worker
+func (d *Dispatcher) Start() {
+	go func() {
+		defer close(d.done)
+		for event := range d.queue {
+			d.deliver(event)
+		}
+	}()
+}
+
+// Publish queues an event for delivery. When the queue is full or the
+// dispatcher is stopped the event is dropped rather than blocking the caller.
+func (d *Dispatcher) Publish(event customer.Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return
+	}
+	select {
+	case d.queue <- event:
+	default:
+		slog.Warn("webhook queue full, dropping event", "type", event.Type, "customer_id", event.CustomerID)
+	}
+}
+
+// Stop stops accepting events and waits for the queued ones to be delivered.
+// When ctx ends first, pending deliveries are abandoned.
+func (d *Dispatcher) Stop(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+
+	select {
+	case <-d.done:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-d.done
+		return fmt.Errorf("stop webhook dispatcher: %w", ctx.Err())
+	}
+}
+
+// deliver POSTs one event, retrying with exponential backoff on network
+// errors, 429 and 5xx responses
+func (d *Dispatcher) deliver(event customer.Event) {
+	body, err := json.Marshal(payload{
+		Type:       event.Type,
+		CustomerID: event.CustomerID,
+		OccurredAt: event.OccurredAt.UTC(),
+	})
+	if err != nil {
+		slog.Error("webhook payload", "error", err)
+		return
+	}
+	signature := "sha256=" + sign(d.secret, body)
+
+	policy := backoff.WithContext(backoff.WithMaxRetries(backoff.NewExponentialBackOff(), maxRetries), d.ctx)
+	err = backoff.Retry(func() error {
+		return d.post(event.Type, body, signature)
+	}, policy)
+	if err != nil {
+		slog.Error("webhook delivery failed", "type", event.Type, "customer_id", event.CustomerID, "error", err)
+	}
+}
+
+func (d *Dispatcher) post(eventType customer.EventType, body []byte, signature string) error {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, d.url, bytes.NewReader(body))
+	if err != nil {
+		return backoff.Permanent(fmt.Errorf("build webhook request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(eventType))
+	req.Header.Set(SignatureHeader, signature)
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send webhook: %w", err)
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("send webhook: unexpected status %d", resp.StatusCode)
+	default:
+		// The receiver rejected the event; sending it again will not help
+		return backoff.Permanent(fmt.Errorf("send webhook: unexpected status %d", resp.StatusCode))
+	}
+}
+
+// sign returns the hex HMAC-SHA256 of body
+func sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}